@@ -0,0 +1,391 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/history"
+)
+
+const (
+	// defaultSessionsLimit sessions returned when no limit is given
+	defaultSessionsLimit = 100
+	// maxSessionsLimit maximum sessions returned by one request
+	maxSessionsLimit = 1000
+)
+
+// apiError body of failed API requests
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// sessionsResponse body of the session endpoints
+type sessionsResponse struct {
+	Sessions []*history.Session `json:"sessions"`
+}
+
+// serversResponse body of the servers endpoint
+type serversResponse struct {
+	Servers []*serverResponse `json:"servers"`
+}
+
+// serverResponse a server with its latest data
+type serverResponse struct {
+	Name    string            `json:"name"`
+	Address string            `json:"address"`
+	Mode    config.QueryMode  `json:"mode"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Health  serverHealth      `json:"health"`
+	// Status data of the last successful scrape, nil if there was none
+	Status *serverStatusResponse `json:"status"`
+}
+
+// serverHealth connection health of a server
+type serverHealth struct {
+	// Up whether the last scrape of the server succeeded
+	Up          bool       `json:"up"`
+	Degraded    bool       `json:"degraded"`
+	LastScrape  *time.Time `json:"lastScrape,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
+// serverStatusResponse data of a server
+type serverStatusResponse struct {
+	Hostname    string              `json:"hostname"`
+	Map         string              `json:"map"`
+	Version     string              `json:"version,omitempty"`
+	PlayerCount playerCountResponse `json:"playerCount"`
+	// Players omitted when hidden by the privacy settings
+	Players []*playerResponse `json:"players,omitempty"`
+}
+
+// playerCountResponse player counts of a server
+type playerCountResponse struct {
+	Current int `json:"current"`
+	Max     int `json:"max"`
+	Humans  int `json:"humans"`
+	Bots    int `json:"bots"`
+}
+
+// playerResponse a player, the fields besides the name are only set with the
+// full player privacy setting
+type playerResponse struct {
+	Name    string `json:"name"`
+	SteamID string `json:"steamID,omitempty"`
+	Ping    int    `json:"ping,omitempty"`
+	Loss    int    `json:"loss,omitempty"`
+}
+
+// newAPIHandler returns the handler of the JSON API endpoints
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/servers", serversHandler)
+	mux.HandleFunc("GET /api/v1/servers/{name}", serverHandler)
+	mux.HandleFunc("GET /api/v1/servers/{name}/sessions", serverSessionsHandler)
+	mux.HandleFunc("GET /api/v1/players", playerSessionsHandler)
+	return corsHandler(mux)
+}
+
+// corsHandler adds the CORS headers for the origins allowed in the config and
+// answers preflight requests
+func corsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins := cc.apiConfig().CORSAllowedOrigins
+		origin := r.Header.Get("Origin")
+		if len(origins) == 0 || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		switch {
+		case slices.Contains(origins, "*"):
+			h.Set("Access-Control-Allow-Origin", "*")
+		case slices.Contains(origins, origin):
+			h.Set("Access-Control-Allow-Origin", origin)
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", "ETag")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "If-None-Match")
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newServerResponse returns the server with its latest data, players are
+// included as allowed by privacy
+func newServerResponse(name string, server config.Server, privacy config.PlayerPrivacy) *serverResponse {
+	out := &serverResponse{
+		Name:    name,
+		Address: server.Address,
+		Mode:    server.Mode,
+		Labels:  server.Labels,
+	}
+
+	status, ok := getServerStatus(server.Address)
+	if !ok {
+		return out
+	}
+	out.Health = serverHealth{
+		Up:         status.LastError == nil,
+		Degraded:   status.Degraded,
+		LastScrape: &status.LastScrape,
+	}
+	if status.LastError != nil {
+		out.Health.LastError = status.LastError.Error()
+	}
+	if status.Snapshot == nil {
+		return out
+	}
+	out.Health.LastSuccess = &status.LastSuccess
+
+	snapshot := status.Snapshot
+	out.Status = &serverStatusResponse{
+		Hostname: snapshot.Status.Hostname,
+		Map:      snapshot.Status.Map,
+		Version:  snapshot.Status.Version,
+		PlayerCount: playerCountResponse{
+			Current: snapshot.Status.PlayerCount.Current,
+			Max:     snapshot.Status.PlayerCount.Max,
+			Humans:  snapshot.Status.PlayerCount.Humans,
+			Bots:    snapshot.Status.PlayerCount.Bots,
+		},
+	}
+	if privacy != config.PlayerPrivacyNames && privacy != config.PlayerPrivacyFull {
+		return out
+	}
+	out.Status.Players = make([]*playerResponse, 0, len(snapshot.Players))
+	for _, p := range snapshot.Players {
+		player := &playerResponse{Name: p.Username}
+		if privacy == config.PlayerPrivacyFull {
+			player.SteamID = p.SteamID
+			player.Ping = p.Ping
+			player.Loss = p.Loss
+		}
+		out.Status.Players = append(out.Status.Players, player)
+	}
+	slices.SortFunc(out.Status.Players, func(a, b *playerResponse) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+// serversHandler returns all servers with their latest data
+func serversHandler(w http.ResponseWriter, r *http.Request) {
+	privacy := cc.apiConfig().Players
+	servers := cc.servers()
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	out := serversResponse{Servers: make([]*serverResponse, 0, len(names))}
+	for _, name := range names {
+		out.Servers = append(out.Servers, newServerResponse(name, servers[name], privacy))
+	}
+	writeCachedJSON(w, r, out)
+}
+
+// serverHandler returns the server given by name or address with its latest
+// data
+func serverHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	for n, server := range cc.servers() {
+		if n == name || server.Address == name {
+			writeCachedJSON(w, r, newServerResponse(n, server, cc.apiConfig().Players))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "unknown server %q", name)
+}
+
+// writeCachedJSON writes v as JSON response with an ETag of its content, a
+// request with a matching If-None-Match header gets a 304 response
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Errorf("Failed to encode API response: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches returns whether the If-None-Match header matches etag, weak
+// comparison is used as for GET requests
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes v as JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debugf("Failed to write API response: %s", err)
+	}
+}
+
+// writeError writes an API error response
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, apiError{Error: fmt.Sprintf(format, args...)})
+}
+
+// parseTime parses an RFC 3339 time or unix timestamp in seconds
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// sessionFilter returns the filter from the `from`, `to` and `limit` query
+// parameters of the request
+func sessionFilter(r *http.Request) (history.Filter, error) {
+	query := r.URL.Query()
+	f := history.Filter{
+		Limit: defaultSessionsLimit,
+	}
+
+	for name, out := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if value := query.Get(name); value != "" {
+			t, err := parseTime(value)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s %q, must be an RFC 3339 time or unix timestamp", name, value)
+			}
+			*out = t
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxSessionsLimit {
+			return f, fmt.Errorf("invalid limit %q, must be between 1 and %d", value, maxSessionsLimit)
+		}
+		f.Limit = limit
+	}
+	return f, nil
+}
+
+// errSessionsHidden error of the session endpoints hidden by the privacy settings
+const errSessionsHidden = "player sessions are hidden by the api.players setting"
+
+// writeSessions responds with the sessions matching the filter, SteamIDs are
+// only included with the full player privacy setting
+func writeSessions(w http.ResponseWriter, f history.Filter, privacy config.PlayerPrivacy) {
+	recorder := currentPlayerHistory()
+	if recorder == nil {
+		writeError(w, http.StatusNotFound, "player history is not enabled")
+		return
+	}
+
+	sessions, err := recorder.Sessions(f, time.Now())
+	if err != nil {
+		log.Errorf("Failed to query player history: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to query player history")
+		return
+	}
+	if privacy != config.PlayerPrivacyFull {
+		for i, s := range sessions {
+			session := *s
+			session.SteamID = ""
+			sessions[i] = &session
+		}
+	}
+	writeJSON(w, http.StatusOK, sessionsResponse{Sessions: sessions})
+}
+
+// playerSessionsHandler returns the sessions of the player given by the
+// `steamid` query parameter, only with the full player privacy setting as
+// the response links the SteamID to the player's names
+func playerSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	privacy := cc.apiConfig().Players
+	if privacy != config.PlayerPrivacyFull {
+		writeError(w, http.StatusNotFound, errSessionsHidden)
+		return
+	}
+	f, err := sessionFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	f.SteamID = r.URL.Query().Get("steamid")
+	if f.SteamID == "" {
+		writeError(w, http.StatusBadRequest, "steamid query parameter is required")
+		return
+	}
+	writeSessions(w, f, privacy)
+}
+
+// serverSessionsHandler returns the sessions on the server given by name or
+// address, as allowed by the player privacy setting
+func serverSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	privacy := cc.apiConfig().Players
+	if privacy != config.PlayerPrivacyNames && privacy != config.PlayerPrivacyFull {
+		writeError(w, http.StatusNotFound, errSessionsHidden)
+		return
+	}
+	f, err := sessionFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	address, ok := cc.serverAddress(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown server %q", r.PathValue("name"))
+		return
+	}
+	f.Server = address
+	writeSessions(w, f, privacy)
+}
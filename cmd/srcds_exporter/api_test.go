@@ -0,0 +1,351 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/history"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/galexrt/srcds_exporter/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withPlayerHistory sets up the config with the privacy setting and a player
+// history with a session of [U:1:1] on server1, which started at start and
+// lasted a minute
+func withPlayerHistory(t *testing.T, start time.Time, privacy config.PlayerPrivacy) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	recorder, err := history.NewRecorder(store, 0)
+	require.NoError(t, err)
+
+	players := map[string]*models.Player{
+		"Player": {Username: "Player", SteamID: "[U:1:1]"},
+	}
+	require.NoError(t, recorder.Observe("127.0.0.1:27015", players, start))
+	require.NoError(t, recorder.Observe("127.0.0.1:27015", players, start.Add(time.Minute)))
+	require.NoError(t, recorder.Observe("127.0.0.1:27015", nil, start.Add(2*time.Minute)))
+
+	withConfig(t, &config.Config{
+		API: config.API{Players: privacy},
+		Servers: map[string]config.Server{
+			"server1": {Address: "127.0.0.1:27015"},
+		},
+	})
+	oldHistory := playerHistory
+	playerHistory = recorder
+	t.Cleanup(func() {
+		playerHistory = oldHistory
+		store.Close()
+	})
+}
+
+// withConfig makes c the active config
+func withConfig(t *testing.T, c *config.Config) {
+	oldCC := cc
+	cc = &CurrentConfig{C: c, effective: c}
+	t.Cleanup(func() { cc = oldCC })
+}
+
+func TestSessionsAPI(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	withPlayerHistory(t, start, config.PlayerPrivacyFull)
+	handler := newHTTPHandler()
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		sessions int
+		err      string
+	}{
+		{name: "player", url: "/api/v1/players?steamid=[U:1:1]", status: http.StatusOK, sessions: 1},
+		{name: "unknown player", url: "/api/v1/players?steamid=[U:1:2]", status: http.StatusOK},
+		{name: "missing steamid", url: "/api/v1/players", status: http.StatusBadRequest, err: "steamid query parameter is required"},
+		{name: "server by name", url: "/api/v1/servers/server1/sessions", status: http.StatusOK, sessions: 1},
+		{name: "server by address", url: "/api/v1/servers/127.0.0.1:27015/sessions", status: http.StatusOK, sessions: 1},
+		{name: "unknown server", url: "/api/v1/servers/server2/sessions", status: http.StatusNotFound, err: `unknown server "server2"`},
+		{name: "time range", url: "/api/v1/servers/server1/sessions?from=2026-01-01T11:00:00Z&to=2026-01-01T12:30:00Z", status: http.StatusOK, sessions: 1},
+		{name: "time range after", url: "/api/v1/servers/server1/sessions?from=" + "1767272400", status: http.StatusOK},
+		{name: "invalid time", url: "/api/v1/players?steamid=[U:1:1]&from=yesterday", status: http.StatusBadRequest, err: `invalid from "yesterday", must be an RFC 3339 time or unix timestamp`},
+		{name: "invalid limit", url: "/api/v1/players?steamid=[U:1:1]&limit=0", status: http.StatusBadRequest, err: `invalid limit "0", must be between 1 and 1000`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.url, nil))
+			require.Equal(t, test.status, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			if test.err != "" {
+				var body apiError
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, test.err, body.Error)
+				return
+			}
+			var body sessionsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body.Sessions, test.sessions)
+			if test.sessions > 0 {
+				assert.Equal(t, "[U:1:1]", body.Sessions[0].SteamID)
+				assert.Equal(t, "127.0.0.1:27015", body.Sessions[0].Server)
+				assert.Equal(t, time.Minute, body.Sessions[0].Duration())
+			}
+		})
+	}
+}
+
+func TestSessionsAPIPrivacy(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		privacy config.PlayerPrivacy
+		url     string
+		status  int
+		steamID string
+	}{
+		{name: "hidden player", privacy: config.PlayerPrivacyHidden, url: "/api/v1/players?steamid=[U:1:1]", status: http.StatusNotFound},
+		{name: "hidden server", privacy: config.PlayerPrivacyHidden, url: "/api/v1/servers/server1/sessions", status: http.StatusNotFound},
+		{name: "names player", privacy: config.PlayerPrivacyNames, url: "/api/v1/players?steamid=[U:1:1]", status: http.StatusNotFound},
+		{name: "names server", privacy: config.PlayerPrivacyNames, url: "/api/v1/servers/server1/sessions", status: http.StatusOK},
+		{name: "full player", privacy: config.PlayerPrivacyFull, url: "/api/v1/players?steamid=[U:1:1]", status: http.StatusOK, steamID: "[U:1:1]"},
+		{name: "full server", privacy: config.PlayerPrivacyFull, url: "/api/v1/servers/server1/sessions", status: http.StatusOK, steamID: "[U:1:1]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withPlayerHistory(t, start, test.privacy)
+			rec := httptest.NewRecorder()
+			newHTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.url, nil))
+			require.Equal(t, test.status, rec.Code)
+
+			if test.status != http.StatusOK {
+				var body apiError
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, errSessionsHidden, body.Error)
+				assert.NotContains(t, rec.Body.String(), "Player")
+				return
+			}
+			if test.steamID == "" {
+				assert.NotContains(t, rec.Body.String(), "steamID")
+				assert.NotContains(t, rec.Body.String(), "[U:1:1]")
+			}
+			var body sessionsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body.Sessions, 1)
+			assert.Equal(t, "Player", body.Sessions[0].Name)
+			assert.Equal(t, test.steamID, body.Sessions[0].SteamID)
+		})
+	}
+}
+
+func TestSessionsAPIDisabled(t *testing.T) {
+	withConfig(t, &config.Config{API: config.API{Players: config.PlayerPrivacyFull}})
+	rec := httptest.NewRecorder()
+	newHTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/players?steamid=[U:1:1]", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "player history is not enabled")
+}
+
+// withServerStatuses sets up two servers, server1 with a successful and
+// server2 with a failed scrape
+func withServerStatuses(t *testing.T, api config.API) time.Time {
+	withConfig(t, &config.Config{
+		API: api,
+		Servers: map[string]config.Server{
+			"server1": {Address: "127.0.0.1:27015", Mode: config.RCONMode, Labels: map[string]string{"region": "eu"}},
+			"server2": {Address: "127.0.0.1:27016", Mode: config.A2SMode},
+		},
+	})
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := map[string]*collector.ServerSnapshot{
+		"127.0.0.1:27015": {
+			Server: "127.0.0.1:27015",
+			Snapshot: &models.Snapshot{
+				Status: models.Status{
+					Hostname:    "Server 1",
+					Map:         "de_dust2",
+					PlayerCount: models.PlayerCount{Current: 2, Max: 24, Humans: 2},
+				},
+				Players: map[string]*models.Player{
+					"Zed":   {Username: "Zed", SteamID: "[U:1:2]", Ping: 50, IP: "203.0.113.2"},
+					"Alice": {Username: "Alice", SteamID: "[U:1:1]", Ping: 20, Loss: 1, IP: "203.0.113.1"},
+				},
+			},
+		},
+	}
+	errs := map[string]error{"127.0.0.1:27016": errors.New("i/o timeout")}
+	recordServerStatuses(snapshots, errs, now)
+	t.Cleanup(func() { recordServerStatuses(nil, nil, now) })
+	return now
+}
+
+func TestServersAPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		privacy  config.PlayerPrivacy
+		expected []*playerResponse
+	}{
+		{name: "hidden", privacy: config.PlayerPrivacyHidden},
+		{
+			name:    "names",
+			privacy: config.PlayerPrivacyNames,
+			expected: []*playerResponse{
+				{Name: "Alice"},
+				{Name: "Zed"},
+			},
+		},
+		{
+			name:    "full",
+			privacy: config.PlayerPrivacyFull,
+			expected: []*playerResponse{
+				{Name: "Alice", SteamID: "[U:1:1]", Ping: 20, Loss: 1},
+				{Name: "Zed", SteamID: "[U:1:2]", Ping: 50},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := withServerStatuses(t, config.API{Players: test.privacy})
+
+			rec := httptest.NewRecorder()
+			newHTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/servers", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "203.0.113")
+
+			var body serversResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body.Servers, 2)
+
+			server1 := body.Servers[0]
+			assert.Equal(t, "server1", server1.Name)
+			assert.Equal(t, config.RCONMode, server1.Mode)
+			assert.Equal(t, map[string]string{"region": "eu"}, server1.Labels)
+			assert.True(t, server1.Health.Up)
+			assert.True(t, now.Equal(*server1.Health.LastSuccess))
+			require.NotNil(t, server1.Status)
+			assert.Equal(t, "de_dust2", server1.Status.Map)
+			assert.Equal(t, 2, server1.Status.PlayerCount.Current)
+			assert.Equal(t, test.expected, server1.Status.Players)
+
+			server2 := body.Servers[1]
+			assert.Equal(t, "server2", server2.Name)
+			assert.False(t, server2.Health.Up)
+			assert.Equal(t, "i/o timeout", server2.Health.LastError)
+			assert.Nil(t, server2.Health.LastSuccess)
+			assert.Nil(t, server2.Status)
+		})
+	}
+}
+
+func TestServerAPI(t *testing.T) {
+	withServerStatuses(t, config.API{Players: config.PlayerPrivacyNames})
+	handler := newHTTPHandler()
+
+	for _, url := range []string{"/api/v1/servers/server1", "/api/v1/servers/127.0.0.1:27015"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body serverResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "server1", body.Name)
+		assert.Equal(t, "Server 1", body.Status.Hostname)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/servers/server3", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServersAPIETag(t *testing.T) {
+	now := withServerStatuses(t, config.API{Players: config.PlayerPrivacyNames})
+	handler := newHTTPHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/servers", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/servers", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// The next scrape changes the content
+	recordServerStatuses(nil, nil, now.Add(time.Minute))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+}
+
+func TestAPICORS(t *testing.T) {
+	tests := []struct {
+		name     string
+		origins  []string
+		origin   string
+		expected string
+	}{
+		{name: "disabled", origin: "https://example.com"},
+		{name: "allowed", origins: []string{"https://example.com"}, origin: "https://example.com", expected: "https://example.com"},
+		{name: "not allowed", origins: []string{"https://example.com"}, origin: "https://example.org"},
+		{name: "all", origins: []string{"*"}, origin: "https://example.org", expected: "*"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withServerStatuses(t, config.API{Players: config.PlayerPrivacyNames, CORSAllowedOrigins: test.origins})
+			handler := newHTTPHandler()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/servers", nil)
+			req.Header.Set("Origin", test.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, test.expected, rec.Header().Get("Access-Control-Allow-Origin"))
+
+			req = httptest.NewRequest(http.MethodOptions, "/api/v1/servers", nil)
+			req.Header.Set("Origin", test.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			req.Header.Set("Access-Control-Request-Headers", "If-None-Match")
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if test.expected == "" {
+				assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+				return
+			}
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, "If-None-Match", rec.Header().Get("Access-Control-Allow-Headers"))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/galexrt/srcds_exporter/config"
+	flag "github.com/spf13/pflag"
+)
+
+// checkConfig implements the `check-config` subcommand, which loads and
+// validates the config file without connecting to any server.
+// Returns the exit code.
+func checkConfig(args []string) int {
+	return runCheckConfig(args, os.Stdout, os.Stderr)
+}
+
+func runCheckConfig(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("srcds_exporter check-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("config.file", "./srcds.yaml", "Config file to check.")
+	enabledCollectors := fs.String("collectors.enabled", defaultCollectors, "Comma separated list of active collectors")
+	a2sEnabled := fs.Bool("a2s", false, "Whether the exporter runs with A2S query support enabled.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	c, err := config.LoadFile(*configFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "FAILED: %s: %s\n", *configFile, err)
+		return 1
+	}
+
+	var problems config.ValidationErrors
+	addErr := func(err error) {
+		var verrs config.ValidationErrors
+		var verr config.ValidationError
+		switch {
+		case errors.As(err, &verrs):
+			problems = append(problems, verrs...)
+		case errors.As(err, &verr):
+			problems = append(problems, verr)
+		default:
+			problems = append(problems, config.ValidationError{Message: err.Error()})
+		}
+	}
+	if err := c.Validate(); err != nil {
+		addErr(err)
+	}
+	if _, err := loadCollectors(c, *enabledCollectors); err != nil {
+		addErr(err)
+	}
+	if !*a2sEnabled {
+		problems = append(problems, a2sProblems(c)...)
+	}
+
+	if len(problems) > 0 {
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].Line < problems[j].Line
+		})
+		fmt.Fprintf(stderr, "FAILED: %s: %d problem(s) found\n", *configFile, len(problems))
+		for _, problem := range problems {
+			fmt.Fprintf(stderr, "  %s\n", problem)
+		}
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "SUCCESS: %s is valid, %d server(s) configured\n", *configFile, len(c.Servers))
+	return 0
+}
+
+// a2sProblems reports the servers which can't be connected to without the
+// --a2s flag
+func a2sProblems(c *config.Config) config.ValidationErrors {
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems config.ValidationErrors
+	for _, name := range names {
+		if mode := c.Servers[name].Mode; mode.RequiresA2S() {
+			problems = append(problems, config.ValidationError{
+				Line:    c.Line("servers", name, "mode"),
+				Message: fmt.Sprintf("server %q: mode %s requires the --a2s flag", name, mode),
+			})
+		}
+	}
+	return problems
+}
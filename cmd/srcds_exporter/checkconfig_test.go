@@ -0,0 +1,106 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{
+			name: "valid",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+`,
+			want: "SUCCESS: srcds.yaml is valid, 1 server(s) configured\n",
+		},
+		{
+			name: "unknown collector with line",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+    collectors:
+      - playercount
+      - nope
+`,
+			wantCode: 1,
+			want: `FAILED: srcds.yaml: 1 problem(s) found
+  line 5: server "server1": collector 'nope' not available
+`,
+		},
+		{
+			name: "A2S server without --a2s",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: RCOM
+  server2:
+    address: 127.0.0.1:27016
+    mode: A2S
+  server3:
+    address: 127.0.0.1:27017
+    mode: hybrid
+    rconPassword: test
+`,
+			wantCode: 1,
+			want: `FAILED: srcds.yaml: 3 problem(s) found
+  line 4: server "server1": unknown mode "RCOM", must be one of RCON, ServerQuery, A2S, GoldSrcRCON, hybrid
+  line 7: server "server2": mode A2S requires the --a2s flag
+  line 10: server "server3": mode hybrid requires the --a2s flag
+`,
+		},
+		{
+			name: "A2S server with --a2s",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: A2S
+`,
+			args: []string{"--a2s"},
+			want: "SUCCESS: srcds.yaml is valid, 1 server(s) configured\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "srcds.yaml"), []byte(tt.config), 0o600))
+			t.Chdir(dir)
+
+			var stdout, stderr bytes.Buffer
+			code := runCheckConfig(append([]string{"--config.file", "srcds.yaml"}, tt.args...), &stdout, &stderr)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.want, stdout.String()+stderr.String())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/history"
+	"github.com/galexrt/srcds_exporter/state"
+)
+
+var (
+	playerHistoryMu sync.RWMutex
+	playerHistory   *history.Recorder
+	// playerHistoryStore and playerHistoryRetention the recorder was created with
+	playerHistoryStore     *state.Store
+	playerHistoryRetention time.Duration
+)
+
+// applyPlayerHistory creates the player history recorder when enabled in c,
+// the recorder is kept as long as the state store and retention don't change.
+// Must be called after applyStateStore.
+func applyPlayerHistory(c *config.Config) {
+	playerHistoryMu.Lock()
+	defer playerHistoryMu.Unlock()
+
+	if c.PlayerHistory == nil || stateStore == nil {
+		if c.PlayerHistory != nil {
+			log.Error("Player history is disabled as the state store isn't available")
+		}
+		playerHistory, playerHistoryStore = nil, nil
+		return
+	}
+	if playerHistory != nil && playerHistoryStore == stateStore && playerHistoryRetention == c.PlayerHistory.Retention {
+		return
+	}
+
+	recorder, err := history.NewRecorder(stateStore, c.PlayerHistory.Retention)
+	if err != nil {
+		log.Errorf("Error loading player history: %s", err)
+		playerHistory, playerHistoryStore = nil, nil
+		return
+	}
+	playerHistory = recorder
+	playerHistoryStore = stateStore
+	playerHistoryRetention = c.PlayerHistory.Retention
+}
+
+// currentPlayerHistory returns the player history recorder, nil when disabled
+func currentPlayerHistory() *history.Recorder {
+	playerHistoryMu.RLock()
+	defer playerHistoryMu.RUnlock()
+	return playerHistory
+}
+
+// recordPlayers records the players of the snapshots in the player history.
+// Snapshots with players not from RCON (A2S modes, hybrid connections when
+// RCON failed) are skipped, as their players lack the SteamIDs and all
+// sessions would end.
+func recordPlayers(snapshots map[string]*collector.ServerSnapshot) {
+	recorder := currentPlayerHistory()
+	if recorder == nil {
+		return
+	}
+
+	for server, snapshot := range snapshots {
+		if snapshot.Players == nil || !snapshot.PlayersFromRCON {
+			continue
+		}
+		if err := recorder.Observe(server, snapshot.Players, snapshot.Time); err != nil {
+			log.Errorf("Failed to record player history of server %s: %s", server, err)
+		}
+	}
+}
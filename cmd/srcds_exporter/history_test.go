@@ -0,0 +1,68 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/history"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/galexrt/srcds_exporter/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordPlayersSkipsNonRCONPlayers(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	recorder, err := history.NewRecorder(store, 0)
+	require.NoError(t, err)
+	oldHistory := playerHistory
+	playerHistory = recorder
+	t.Cleanup(func() { playerHistory = oldHistory })
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rconPlayers := map[string]*models.Player{
+		"STEAM_1:0:1": {Username: "Alice", SteamID: "STEAM_1:0:1"},
+	}
+	// A2S players of a hybrid connection whose RCON scrape failed once
+	a2sPlayers := map[string]*models.Player{
+		"Alice": {Username: "Alice"},
+	}
+	for i, snapshot := range []*models.Snapshot{
+		{Players: rconPlayers, PlayersFromRCON: true},
+		{Players: a2sPlayers},
+		{Players: rconPlayers, PlayersFromRCON: true},
+	} {
+		snapshot.Time = start.Add(time.Duration(i) * time.Minute)
+		recordPlayers(map[string]*collector.ServerSnapshot{
+			"127.0.0.1:27015": {Server: "127.0.0.1:27015", Snapshot: snapshot},
+		})
+	}
+
+	sessions, err := recorder.Sessions(history.Filter{Server: "127.0.0.1:27015"}, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "STEAM_1:0:1", sessions[0].SteamID)
+	assert.True(t, sessions[0].Online)
+	assert.True(t, start.Equal(sessions[0].Start))
+	assert.True(t, start.Add(2*time.Minute).Equal(sessions[0].End))
+}
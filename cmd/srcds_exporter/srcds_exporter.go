@@ -17,12 +17,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -35,16 +38,22 @@ import (
 	"github.com/galexrt/srcds_exporter/config"
 	"github.com/galexrt/srcds_exporter/connector"
 	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/galexrt/srcds_exporter/discovery"
+	"github.com/galexrt/srcds_exporter/logs"
+	"github.com/galexrt/srcds_exporter/notify"
 	"github.com/kardianos/service"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
 	"github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v3"
 )
 
 const (
 	defaultCollectors = "map,playercount"
+
+	scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
 )
 
 var (
@@ -60,6 +69,24 @@ var (
 		[]string{"collector"},
 		nil,
 	)
+	serverDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(collector.Namespace, "scrape", "server_duration_seconds"),
+		"srcds_exporter: Duration of fetching the data of a server.",
+		[]string{"server"},
+		nil,
+	)
+	serverSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(collector.Namespace, "scrape", "server_success"),
+		"srcds_exporter: Whether fetching the data of a server succeeded.",
+		[]string{"server"},
+		nil,
+	)
+	connectionDegradedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(collector.Namespace, "connection", "degraded"),
+		"Whether the connection to the server fell back to a reduced set of data, e.g., A2S only in hybrid mode after RCON authentication failed.",
+		[]string{"server"},
+		nil,
+	)
 )
 
 type program struct{}
@@ -72,6 +99,8 @@ type CmdLineOpts struct {
 
 	metricsAddr           string
 	metricsPath           string
+	webConfigFile         string
+	webSystemdSocket      bool
 	enabledCollectors     string
 	configFile            string
 	reloadEndpointEnabled bool
@@ -79,22 +108,42 @@ type CmdLineOpts struct {
 	cachingEnabled bool
 	cacheDuration  int64
 
+	scrapeTimeout        time.Duration
+	scrapeTimeoutOffset  time.Duration
+	scrapeMaxConcurrency int
+
 	a2sEnabled bool
+
+	logsListenAddress string
+	logsSecret        string
 }
 
 var (
-	log      = logrus.New()
-	opts     CmdLineOpts
-	flags    = flag.NewFlagSet("srcds_exporter", flag.ExitOnError)
-	cons     *connector.Connector
-	cc       *CurrentConfig
-	reloadCh chan chan error
+	log   = logrus.New()
+	opts  CmdLineOpts
+	flags = flag.NewFlagSet("srcds_exporter", flag.ExitOnError)
+	cons  *connector.Connector
+	cc    *CurrentConfig
+
+	discoveryManager *discovery.Manager
+	logReceiver      *logs.Receiver
+	notifier         *notify.Notifier
+	reloadCh         chan chan error
+
+	srcdsCollector *SRCDSCollector
 )
 
+// collectorInstance a collector with the settings it was created with
+type collectorInstance struct {
+	collector.Collector
+	settings *collector.Settings
+}
+
 // SRCDSCollector contains the collectors to be used
 type SRCDSCollector struct {
 	lastCollectTime time.Time
-	collectors      map[string]collector.Collector
+	collectors      map[string]*collectorInstance
+	collectorsMutex sync.RWMutex
 
 	// Cache related
 	cachingEnabled bool
@@ -104,6 +153,10 @@ type SRCDSCollector struct {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check-config" {
+		os.Exit(checkConfig(os.Args[2:]))
+	}
+
 	// Service setup
 	svcConfig := &service.Config{
 		Name:        "SRCDSExporter",
@@ -127,6 +180,10 @@ func main() {
 type CurrentConfig struct {
 	sync.RWMutex
 	C *config.Config
+	// discovered servers found by service discovery
+	discovered map[string]config.Server
+	// effective config with the discovered servers merged in
+	effective *config.Config
 }
 
 func (p *program) Start(s service.Service) error {
@@ -181,12 +238,31 @@ func (p *program) Start(s service.Service) error {
 		C: &config.Config{},
 	}
 
+	collector.SetConnector(cons)
+	collector.SetMaxConcurrency(opts.scrapeMaxConcurrency)
+
+	// Registered per scrape request, see scrapeHandler
+	srcdsCollector = NewSRCDSCollector(opts.cachingEnabled, opts.cacheDuration)
+
+	discoveryManager = discovery.NewManager(log, cc.updateDiscovered)
+	notifier = notify.NewNotifier(log)
+
+	if opts.logsListenAddress != "" {
+		conn, err := net.ListenPacket("udp", opts.logsListenAddress)
+		if err != nil {
+			log.Fatalf("Error listening for server logs: %s", err)
+		}
+		log.Infof("Receiving server logs on %s", conn.LocalAddr())
+		logReceiver = logs.NewReceiver(log, conn, opts.logsSecret)
+		go logReceiver.Run(context.Background(), collector.HandleLogMessage)
+	}
+
 	if err := cc.reloadConfig(opts.configFile); err != nil {
 		log.Fatalf("Error loading config: %s", err)
 	}
 
-	hup := make(chan os.Signal)
-	reloadCh := make(chan chan error)
+	hup := make(chan os.Signal, 1)
+	reloadCh = make(chan chan error)
 	signal.Notify(hup, syscall.SIGHUP)
 	go func() {
 		for {
@@ -205,20 +281,6 @@ func (p *program) Start(s service.Service) error {
 			}
 		}
 	}()
-	collector.SetConnector(cons)
-
-	collectors, err := loadCollectors(opts.enabledCollectors)
-	if err != nil {
-		log.Fatalf("Couldn't load collectors: %s", err)
-	}
-	log.Infof("Enabled collectors:")
-	for n := range collectors {
-		log.Infof(" - %s", n)
-	}
-
-	if err = prometheus.Register(NewSRCDSCollector(collectors, opts.cachingEnabled, opts.cacheDuration)); err != nil {
-		log.Fatalf("Couldn't register collector: %s", err)
-	}
 
 	// non-blocking start
 	go p.run()
@@ -230,11 +292,11 @@ func (p *program) Stop(s service.Service) error {
 	return nil
 }
 
-func NewSRCDSCollector(collectors map[string]collector.Collector, cachingEnabled bool, cacheDurationSeconds int64) *SRCDSCollector {
+func NewSRCDSCollector(cachingEnabled bool, cacheDurationSeconds int64) *SRCDSCollector {
 	return &SRCDSCollector{
 		cache:           make([]prometheus.Metric, 0),
 		lastCollectTime: time.Unix(0, 0),
-		collectors:      collectors,
+		collectors:      map[string]*collectorInstance{},
 		cachingEnabled:  cachingEnabled,
 		cacheDuration:   time.Duration(cacheDurationSeconds) * time.Second,
 	}
@@ -249,11 +311,20 @@ func init() {
 
 	flags.StringVar(&opts.metricsAddr, "web.listen-address", ":9137", "The address to listen on for HTTP requests")
 	flags.StringVar(&opts.metricsPath, "web.telemetry-path", "/metrics", "Path the metrics will be exposed under")
+	flags.StringVar(&opts.webConfigFile, "web.config.file", "", "Path to a web config file enabling TLS, HTTP/2 and/or basic auth, see https://github.com/prometheus/exporter-toolkit/blob/master/docs/web-configuration.md")
+	flags.BoolVar(&opts.webSystemdSocket, "web.systemd-socket", false, "Use systemd socket activation listeners instead of the listen address.")
 	flags.BoolVar(&opts.reloadEndpointEnabled, "web.reload-endpoint-enabled", false, "Enable/Disable the POST config reload endpoint.")
 
 	flags.StringVar(&opts.configFile, "config.file", "./srcds.yaml", "Config file to use.")
 
-	flags.BoolVar(&opts.a2sEnabled, "a2s", false, "Enable A2S query support (opt-in, required for servers configured with mode: A2S).")
+	flags.DurationVar(&opts.scrapeTimeout, "scrape.timeout", 10*time.Second, "Scrape timeout used when Prometheus doesn't send the "+scrapeTimeoutHeader+" header, 0 disables the timeout.")
+	flags.DurationVar(&opts.scrapeTimeoutOffset, "scrape.timeout-offset", 500*time.Millisecond, "Offset to subtract from the scrape timeout, so metrics are returned before Prometheus gives up.")
+	flags.IntVar(&opts.scrapeMaxConcurrency, "scrape.max-concurrency", collector.DefaultMaxConcurrency, "Maximum amount of servers queried at the same time.")
+
+	flags.BoolVar(&opts.a2sEnabled, "a2s", false, "Enable A2S query support (opt-in, required for servers configured with mode: A2S or hybrid).")
+
+	flags.StringVar(&opts.logsListenAddress, "logs.listen-address", "", "UDP address to receive server logs on (added on the servers with logaddress_add), used by the match collector. Disabled when empty.")
+	flags.StringVar(&opts.logsSecret, "logs.secret", "", "Only accept server logs sent with this sv_logsecret.")
 }
 
 func flagNameFromEnvName(s string) string {
@@ -285,36 +356,242 @@ func parseFlagsAndEnvVars() error {
 }
 
 func (cc *CurrentConfig) reloadConfig(confFile string) (err error) {
-	var c = &config.Config{}
+	defer func() { recordReload(err, time.Now()) }()
 
-	yamlFile, err := ioutil.ReadFile(confFile)
+	// Password files and environment variables are read again on every reload
+	c, err := config.LoadFile(confFile)
 	if err != nil {
-		log.Errorf("Error reading config file: %s", err)
+		log.Errorf("Error loading config file: %s", err)
 		return err
 	}
-
-	if err := yaml.Unmarshal(yamlFile, c); err != nil {
-		log.Errorf("Error parsing config file: %s", err)
+	if err := c.Validate(); err != nil {
+		log.Errorf("Invalid config file: %s", err)
 		return err
 	}
 
 	cc.Lock()
-	cc.C = c
-	loadConnections(cc)
+	collectors, err := cc.apply(c, cc.discovered)
 	cc.Unlock()
+	if err != nil {
+		log.Errorf("Error applying config: %s", err)
+		return err
+	}
+
+	log.Infof("Enabled collectors:")
+	for n, coll := range collectors {
+		log.Infof(" - %s (%d servers)", n, len(coll.settings.Servers))
+	}
+
+	// Must be called without holding the lock, as discovery updates lock it
+	discoveryManager.ApplyConfig(discoverers(c))
 
 	log.Infoln("Loaded config file")
 	return nil
 }
 
+// apply makes c together with the discovered servers the active config, the
+// collectors are only replaced when all could be created. cc must be locked.
+func (cc *CurrentConfig) apply(c *config.Config, discovered map[string]config.Server) (map[string]*collectorInstance, error) {
+	effective := mergeServers(c, discovered)
+
+	collectors, err := loadCollectors(effective, opts.enabledCollectors)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.C = c
+	cc.discovered = discovered
+	cc.effective = effective
+	if err := loadConnections(effective); err != nil {
+		log.Errorf("Error loading connections: %s", err)
+	}
+	collector.SetServerLabels(serverLabels(effective))
+	applyStateStore(c.Options)
+	applyPlayerHistory(c)
+	if notifier != nil {
+		names := make(map[string]string, len(effective.Servers))
+		for name, server := range effective.Servers {
+			names[server.Address] = name
+		}
+		notifier.Apply(c.Notifications, names)
+	}
+	srcdsCollector.SetCollectors(collectors)
+	if logReceiver != nil {
+		addresses := make([]string, 0, len(effective.Servers))
+		for _, server := range effective.Servers {
+			addresses = append(addresses, server.Address)
+		}
+		logReceiver.SetServers(addresses)
+	}
+
+	return collectors, nil
+}
+
+// serverAddress returns the address of the configured or discovered server
+// with the given name or address
+func (cc *CurrentConfig) serverAddress(name string) (string, bool) {
+	cc.RLock()
+	defer cc.RUnlock()
+
+	if cc.effective == nil {
+		return "", false
+	}
+	if server, ok := cc.effective.Servers[name]; ok {
+		return server.Address, true
+	}
+	for _, server := range cc.effective.Servers {
+		if server.Address == name {
+			return server.Address, true
+		}
+	}
+	return "", false
+}
+
+// servers returns the configured and discovered servers by name
+func (cc *CurrentConfig) servers() map[string]config.Server {
+	cc.RLock()
+	defer cc.RUnlock()
+	if cc.effective == nil {
+		return map[string]config.Server{}
+	}
+	return cc.effective.Servers
+}
+
+// apiConfig returns the settings of the JSON API
+func (cc *CurrentConfig) apiConfig() config.API {
+	cc.RLock()
+	defer cc.RUnlock()
+	return cc.C.API
+}
+
+// updateDiscovered applies the servers found by service discovery
+func (cc *CurrentConfig) updateDiscovered(servers map[string]config.Server) {
+	cc.Lock()
+	defer cc.Unlock()
+
+	if _, err := cc.apply(cc.C, servers); err != nil {
+		log.Errorf("Error applying discovered servers: %s", err)
+		return
+	}
+	log.Debugf("Applied %d discovered servers", len(servers))
+}
+
+// mergeServers returns a copy of c with the valid discovered servers added,
+// servers from the config file take precedence over discovered servers with
+// the same address
+func mergeServers(c *config.Config, discovered map[string]config.Server) *config.Config {
+	effective := *c
+	effective.Servers = make(map[string]config.Server, len(c.Servers)+len(discovered))
+
+	addresses := map[string]struct{}{}
+	for name, server := range c.Servers {
+		effective.Servers[name] = server
+		addresses[server.Address] = struct{}{}
+	}
+	for name, server := range discovered {
+		if _, ok := addresses[server.Address]; ok {
+			continue
+		}
+		if _, ok := effective.Servers[name]; ok {
+			continue
+		}
+		if server.RCONPassword == "" {
+			server.RCONPassword = c.Options.RCONPassword
+		}
+		if err := server.Validate(); err != nil {
+			log.Errorf("Ignoring discovered server %s: %s", name, err)
+			continue
+		}
+		effective.Servers[name] = server
+		addresses[server.Address] = struct{}{}
+	}
+
+	return &effective
+}
+
+// discoverers returns the service discovery providers enabled in the config
+func discoverers(c *config.Config) map[string]discovery.Discoverer {
+	discoverers := map[string]discovery.Discoverer{}
+	if len(c.ServerFiles) > 0 {
+		discoverers["file"] = discovery.NewFileDiscoverer(log, c.ServerFiles, c.Options.ServerFilesRefreshInterval)
+	}
+	for _, h := range c.HTTPDiscovery {
+		discoverers["http/"+h.Name] = discovery.NewHTTPDiscoverer(log, h)
+	}
+	for _, st := range c.SteamDiscovery {
+		discoverers["steam/"+st.Name] = discovery.NewSteamDiscoverer(log, st)
+	}
+	if c.DockerDiscovery != nil {
+		d, err := discovery.NewDockerDiscoverer(log, *c.DockerDiscovery)
+		if err != nil {
+			log.Errorf("failed to create docker discovery: %s", err)
+		} else {
+			discoverers["docker"] = d
+		}
+	}
+	return discoverers
+}
+
+// SetCollectors replaces the collectors used for the following scrapes
+func (n *SRCDSCollector) SetCollectors(collectors map[string]*collectorInstance) {
+	n.collectorsMutex.Lock()
+	defer n.collectorsMutex.Unlock()
+	n.collectors = collectors
+}
+
+// serverCollectors returns the names of the collectors enabled per server
+func (n *SRCDSCollector) serverCollectors() map[string][]string {
+	n.collectorsMutex.RLock()
+	defer n.collectorsMutex.RUnlock()
+
+	out := map[string][]string{}
+	for name, coll := range n.collectors {
+		for server := range coll.settings.Servers {
+			out[server] = append(out[server], name)
+		}
+	}
+	for _, names := range out {
+		sort.Strings(names)
+	}
+	return out
+}
+
 // Describe implements the prometheus.Collector interface.
 func (n *SRCDSCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- serverDurationDesc
+	ch <- serverSuccessDesc
+	ch <- connectionDegradedDesc
 }
 
 // Collect implements the prometheus.Collector interface.
 func (n *SRCDSCollector) Collect(outgoingCh chan<- prometheus.Metric) {
+	ctx, cancel := scrapeContext(opts.scrapeTimeout)
+	defer cancel()
+	n.collect(ctx, outgoingCh)
+}
+
+// WithContext returns a prometheus.Collector which stops waiting for servers once ctx is done.
+func (n *SRCDSCollector) WithContext(ctx context.Context) prometheus.Collector {
+	return &scrapeCollector{
+		SRCDSCollector: n,
+		ctx:            ctx,
+	}
+}
+
+// scrapeCollector binds a SRCDSCollector to the context of a single scrape.
+type scrapeCollector struct {
+	*SRCDSCollector
+	ctx context.Context
+}
+
+// Collect implements the prometheus.Collector interface.
+func (s *scrapeCollector) Collect(outgoingCh chan<- prometheus.Metric) {
+	s.collect(s.ctx, outgoingCh)
+}
+
+func (n *SRCDSCollector) collect(ctx context.Context, outgoingCh chan<- prometheus.Metric) {
 	if n.cachingEnabled {
 		n.cacheMutex.Lock()
 		defer n.cacheMutex.Unlock()
@@ -349,11 +626,47 @@ func (n *SRCDSCollector) Collect(outgoingCh chan<- prometheus.Metric) {
 		wgOutgoing.Done()
 	}()
 
+	n.collectorsMutex.RLock()
+	collectors := n.collectors
+	n.collectorsMutex.RUnlock()
+
+	// Fetch the data of every server once, all collectors work on the same snapshots
+	snapshots, errs := collector.FetchSnapshots(ctx, collectorCommands(collectors))
+	for server, err := range errs {
+		log.Errorf("Failed to fetch data from server %s: %s", server, err)
+		metricsCh <- prometheus.MustNewConstMetric(serverSuccessDesc, prometheus.GaugeValue, 0, server)
+	}
+	recordServerStatuses(snapshots, errs, time.Now())
+	if err := collector.RecordMapHistory(snapshots); err != nil {
+		log.Errorf("Failed to save map history: %s", err)
+	}
+	recordPlayers(snapshots)
+	if notifier != nil {
+		now := time.Now()
+		for server, err := range errs {
+			notifier.Observe(server, nil, err, now)
+		}
+		for server, snapshot := range snapshots {
+			notifier.Observe(server, snapshot.Snapshot, nil, now)
+		}
+	}
+	for server, snapshot := range snapshots {
+		metricsCh <- prometheus.MustNewConstMetric(serverDurationDesc, prometheus.GaugeValue, snapshot.Duration.Seconds(), server)
+		metricsCh <- prometheus.MustNewConstMetric(serverSuccessDesc, prometheus.GaugeValue, 1, server)
+		if conn, ok := snapshot.Conn.(connections.Degradable); ok {
+			degraded := 0.0
+			if conn.Degraded() {
+				degraded = 1
+			}
+			metricsCh <- prometheus.MustNewConstMetric(connectionDegradedDesc, prometheus.GaugeValue, degraded, server)
+		}
+	}
+
 	wgCollection := sync.WaitGroup{}
-	wgCollection.Add(len(n.collectors))
-	for name, coll := range n.collectors {
-		go func(name string, coll collector.Collector) {
-			execute(name, coll, metricsCh)
+	wgCollection.Add(len(collectors))
+	for name, coll := range collectors {
+		go func(name string, coll *collectorInstance) {
+			execute(name, coll, coll.settings.Filter(snapshots), metricsCh)
 			wgCollection.Done()
 		}(name, coll)
 	}
@@ -372,9 +685,25 @@ func (n *SRCDSCollector) Collect(outgoingCh chan<- prometheus.Metric) {
 	log.Debug("Finished waiting for outgoing Adapter")
 }
 
-func execute(name string, c collector.Collector, ch chan<- prometheus.Metric) {
+// collectorCommands returns the RCON commands to run per server for the
+// collectors implementing collector.CommandCollector
+func collectorCommands(collectors map[string]*collectorInstance) map[string][]string {
+	commands := map[string][]string{}
+	for _, coll := range collectors {
+		cc, ok := coll.Collector.(collector.CommandCollector)
+		if !ok {
+			continue
+		}
+		for server := range coll.settings.Servers {
+			commands[server] = append(commands[server], cc.Commands(server)...)
+		}
+	}
+	return commands
+}
+
+func execute(name string, c collector.Collector, snapshots map[string]*collector.ServerSnapshot, ch chan<- prometheus.Metric) {
 	begin := time.Now()
-	err := c.Update(ch)
+	err := c.Update(snapshots, ch)
 	duration := time.Since(begin)
 	var success float64
 
@@ -389,42 +718,116 @@ func execute(name string, c collector.Collector, ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
 }
 
-func loadConnections(cc *CurrentConfig) error {
-	for name, server := range cc.C.Servers {
-		var err error
-		for i := 0; i < 5; i++ {
-			if err = cons.NewConnection(name,
-				&connections.ConnectionOptions{
-					Addr:                 server.Address,
-					Mode:                 server.Mode,
-					RCONPassword:         server.RCONPassword,
-					ConnectTimeout:       cc.C.Options.ConnectTimeout,
-					CacheCleanupInterval: cc.C.Options.CacheCleanupInterval,
-					CacheExpiration:      cc.C.Options.CacheExpiration,
-				}); err == nil {
-				break
-			}
-		}
-		if err != nil {
-			log.Fatalf("Error connecting to %v server after 5 tries: %+v", server.Address, err)
+// loadConnections creates connections for the servers of the config and
+// removes the connections of servers that are gone
+func loadConnections(c *config.Config) error {
+	var errs []error
+	addresses := make(map[string]struct{}, len(c.Servers))
+	for name, server := range c.Servers {
+		if err := cons.NewConnection(name,
+			&connections.ConnectionOptions{
+				Addr:                 server.Address,
+				Mode:                 server.Mode,
+				RCONPassword:         server.RCONPassword,
+				ConnectTimeout:       c.Options.ConnectTimeout,
+				CacheCleanupInterval: c.Options.CacheCleanupInterval,
+				CacheExpiration:      c.Options.CacheExpiration,
+			}); err != nil {
+			errs = append(errs, fmt.Errorf("error connecting to %v server: %w", server.Address, err))
+			continue
 		}
+		addresses[server.Address] = struct{}{}
 		log.Debugf("Connected to server: %v", server.Address)
 	}
-	return nil
+
+	for addr := range cons.GetConnections() {
+		if _, ok := addresses[addr]; !ok {
+			log.Debugf("Removing connection to server: %v", addr)
+			cons.RemoveConnection(addr)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
-func loadCollectors(list string) (map[string]collector.Collector, error) {
-	collectors := map[string]collector.Collector{}
-	for _, name := range strings.Split(list, ",") {
-		fn, ok := collector.Factories[name]
-		if !ok {
+// serverLabels returns the static labels of the servers keyed by server label
+func serverLabels(c *config.Config) map[string]map[string]string {
+	labels := make(map[string]map[string]string, len(c.Servers))
+	for _, server := range c.Servers {
+		if len(server.Labels) > 0 {
+			labels[server.Address] = server.Labels
+		}
+	}
+	return labels
+}
+
+// loadCollectors creates the collectors enabled for at least one server.
+// Servers without a collectors list use the collectors from the given list.
+func loadCollectors(c *config.Config, list string) (map[string]*collectorInstance, error) {
+	defaults := strings.Split(list, ",")
+
+	settings := map[string]*collector.Settings{}
+	enable := func(name string, server string, options *yaml.Node) error {
+		if _, ok := collector.Factories[name]; !ok {
+			return fmt.Errorf("collector '%s' not available", name)
+		}
+		if _, ok := settings[name]; !ok {
+			settings[name] = &collector.Settings{
+				Servers: map[string]*yaml.Node{},
+			}
+			if node, ok := c.Collectors[name]; ok {
+				settings[name].Options = &node
+			}
+		}
+		settings[name].Servers[server] = options
+		return nil
+	}
+
+	for _, name := range defaults {
+		if _, ok := collector.Factories[name]; !ok {
 			return nil, fmt.Errorf("collector '%s' not available", name)
 		}
-		c, err := fn()
+	}
+	for serverName, server := range c.Servers {
+		names := server.Collectors
+		if len(names) == 0 {
+			names = defaults
+		}
+		for _, name := range names {
+			var options *yaml.Node
+			if node, ok := server.CollectorOptions[name]; ok {
+				options = &node
+			}
+			if err := enable(name, server.Address, options); err != nil {
+				path := []string{"servers", serverName}
+				if len(server.Collectors) > 0 {
+					path = append(path, "collectors")
+				}
+				return nil, config.ValidationError{
+					Line:    c.Line(path...),
+					Message: fmt.Sprintf("server %q: %s", serverName, err),
+				}
+			}
+		}
+	}
+
+	collectors := map[string]*collectorInstance{}
+	for name, s := range settings {
+		coll, err := collector.Factories[name](s)
 		if err != nil {
-			return nil, err
+			line := 0
+			if _, ok := c.Collectors[name]; ok {
+				line = c.Line("collectors", name)
+			}
+			return nil, config.ValidationError{
+				Line:    line,
+				Message: fmt.Sprintf("collector '%s': %s", name, err),
+			}
+		}
+		collectors[name] = &collectorInstance{
+			Collector: coll,
+			settings:  s,
 		}
-		collectors[name] = c
 	}
 	return collectors, nil
 }
@@ -434,47 +837,52 @@ func (p *program) run() {
 	defer cons.CloseAll()
 
 	// Background work
-	handler := promhttp.HandlerFor(prometheus.DefaultGatherer,
+	server := &http.Server{
+		Handler: newHTTPHandler(),
+	}
+	if err := web.ListenAndServe(server, webFlagConfig(), newSlogLogger()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// scrapeHandler serves the metrics, only waiting for servers as long as the
+// scrape timeout sent by Prometheus allows.
+func scrapeHandler(w http.ResponseWriter, r *http.Request) {
+	timeout := opts.scrapeTimeout
+	if v := r.Header.Get(scrapeTimeoutHeader); v != "" {
+		seconds, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Warnf("Failed to parse %s header value %q: %s", scrapeTimeoutHeader, v, err)
+		} else {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+	}
+
+	ctx, cancel := scrapeContext(timeout)
+	defer cancel()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(srcdsCollector.WithContext(ctx))
+
+	handler := promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, registry},
 		promhttp.HandlerOpts{
 			ErrorLog:      log,
 			ErrorHandling: promhttp.ContinueOnError,
 		})
 
-	http.HandleFunc(opts.metricsPath, func(w http.ResponseWriter, r *http.Request) {
-		cc.RLock()
-		handler.ServeHTTP(w, r)
-		cc.RUnlock()
-	})
-
-	// Enable reload endpoint only when enabled by the flag
-	if opts.reloadEndpointEnabled {
-		http.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != "POST" {
-				w.WriteHeader(http.StatusMethodNotAllowed)
-				fmt.Fprintf(w, "This endpoint requires a POST request.\n")
-				return
-			}
+	cc.RLock()
+	handler.ServeHTTP(w, r)
+	cc.RUnlock()
+}
 
-			rc := make(chan error)
-			reloadCh <- rc
-			if err := <-rc; err != nil {
-				http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
-			}
-		})
+// scrapeContext returns a context for a scrape with the given timeout minus
+// the configured offset, a timeout of 0 means no deadline.
+func scrapeContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
 	}
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(`<!DOCTYPE html>
-		<html>
-			<head><title>SRCDS Exporter</title></head>
-			<body>
-				<h1>SRCDS Exporter</h1>
-				<p><a href="` + opts.metricsPath + `">Metrics</a></p>
-			</body>
-		</html>`))
-	})
-
-	log.Info("Listening on " + opts.metricsAddr)
-	if err := http.ListenAndServe(opts.metricsAddr, nil); err != nil {
-		log.Fatal(err)
+	if timeout > opts.scrapeTimeoutOffset {
+		timeout -= opts.scrapeTimeoutOffset
 	}
+	return context.WithTimeout(context.Background(), timeout)
 }
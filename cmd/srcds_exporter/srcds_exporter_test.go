@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCollectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		list    string
+		want    map[string][]string
+		wantErr string
+		// commands of the custom collector per server
+		commands map[string][]string
+	}{
+		{
+			name: "fallback to enabled collectors",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+  server2:
+    address: 127.0.0.1:27016
+    rconPassword: test
+`,
+			list: "playercount,map",
+			want: map[string][]string{
+				"playercount": {"127.0.0.1:27015", "127.0.0.1:27016"},
+				"map":         {"127.0.0.1:27015", "127.0.0.1:27016"},
+			},
+		},
+		{
+			name: "per server collectors",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+    collectors:
+      - players
+  server2:
+    address: 127.0.0.1:27016
+    rconPassword: test
+`,
+			list: "playercount",
+			want: map[string][]string{
+				"players":     {"127.0.0.1:27015"},
+				"playercount": {"127.0.0.1:27016"},
+			},
+		},
+		{
+			name: "collector disabled everywhere isn't instantiated",
+			config: `collectors:
+  custom:
+    commands:
+      - command: "sv_cheats"
+        regex: '"sv_cheats" = "(\d+)"'
+        metric: cheats
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+    collectors:
+      - map
+`,
+			list: "playercount",
+			want: map[string][]string{
+				"map": {"127.0.0.1:27015"},
+			},
+		},
+		{
+			name: "options overridden per server",
+			config: `collectors:
+  custom:
+    commands:
+      - command: "sv_cheats"
+        regex: '"sv_cheats" = "(\d+)"'
+        metric: cheats
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+  server2:
+    address: 127.0.0.1:27016
+    rconPassword: test
+    collectorOptions:
+      custom:
+        commands:
+          - command: "mp_timelimit"
+            regex: '"mp_timelimit" = "(\d+)"'
+            metric: timelimit
+`,
+			list: "custom",
+			want: map[string][]string{
+				"custom": {"127.0.0.1:27015", "127.0.0.1:27016"},
+			},
+			commands: map[string][]string{
+				"127.0.0.1:27015": {"sv_cheats"},
+				"127.0.0.1:27016": {"mp_timelimit"},
+			},
+		},
+		{
+			name: "unknown enabled collector",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+`,
+			list:    "playercount,nope",
+			wantErr: "collector 'nope' not available",
+		},
+		{
+			name: "unknown server collector",
+			config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+    collectors:
+      - nope
+`,
+			list:    "playercount",
+			wantErr: `server "server1": collector 'nope' not available`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := config.Load([]byte(tt.config))
+			require.NoError(t, err)
+
+			collectors, err := loadCollectors(c, tt.list)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			got := map[string][]string{}
+			for name, instance := range collectors {
+				for server := range instance.settings.Servers {
+					got[name] = append(got[name], server)
+				}
+				slices.Sort(got[name])
+			}
+			assert.Equal(t, tt.want, got)
+
+			for server, want := range tt.commands {
+				cc, ok := collectors["custom"].Collector.(collector.CommandCollector)
+				require.True(t, ok)
+				assert.Equal(t, want, cc.Commands(server), server)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/state"
+)
+
+var (
+	stateStore *state.Store
+	// stopCompaction stops compacting the open state store
+	stopCompaction context.CancelFunc
+	// compactionInterval interval of the running compaction
+	compactionInterval time.Duration
+)
+
+// applyStateStore opens the state store configured in o, replacing the open
+// store when the file changed. Errors are logged, the exporter keeps working
+// without persisting its state.
+func applyStateStore(o config.Options) {
+	if stateStore != nil && stateStore.File() == o.StateFile && compactionInterval == o.StateCompactionInterval {
+		return
+	}
+
+	if stopCompaction != nil {
+		stopCompaction()
+		stopCompaction = nil
+	}
+
+	if stateStore != nil && stateStore.File() != o.StateFile {
+		if err := collector.SetStateStore(nil); err != nil {
+			log.Errorf("Error detaching state store: %s", err)
+		}
+		if err := stateStore.Close(); err != nil {
+			log.Errorf("Error closing state store: %s", err)
+		}
+		stateStore = nil
+	}
+	if o.StateFile == "" {
+		return
+	}
+
+	if stateStore == nil {
+		store, err := state.Open(o.StateFile)
+		if err != nil {
+			log.Errorf("Error opening state store: %s", err)
+			return
+		}
+		if err := collector.SetStateStore(store); err != nil {
+			log.Errorf("Error loading state: %s", err)
+		}
+		log.Infof("Persisting state in %s", o.StateFile)
+		stateStore = store
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCompaction = cancel
+	compactionInterval = o.StateCompactionInterval
+	go compactStateStore(ctx, stateStore, o.StateCompactionInterval)
+}
+
+// compactStateStore compacts the store in the given interval until ctx is done
+func compactStateStore(ctx context.Context, store *state.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before, after, err := store.Compact()
+			if err != nil {
+				log.Errorf("Error compacting state store: %s", err)
+				continue
+			}
+			log.Debugf("Compacted state store from %d to %d bytes", before, after)
+		}
+	}
+}
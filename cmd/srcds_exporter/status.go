@@ -0,0 +1,205 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/prometheus/common/version"
+)
+
+var (
+	serverStatusesMu sync.RWMutex
+	// serverStatuses latest scrape results by server address
+	serverStatuses = map[string]*serverStatus{}
+
+	lastReloadMu sync.RWMutex
+	lastReload   reloadStatus
+)
+
+// reloadStatus result of the last config (re)load
+type reloadStatus struct {
+	Time time.Time
+	// Err error of the last reload, nil if it succeeded
+	Err error
+	// LastSuccess time of the last successful reload
+	LastSuccess time.Time
+}
+
+// recordReload records the result of a config (re)load
+func recordReload(err error, now time.Time) {
+	lastReloadMu.Lock()
+	defer lastReloadMu.Unlock()
+	lastReload.Time = now
+	lastReload.Err = err
+	if err == nil {
+		lastReload.LastSuccess = now
+	}
+}
+
+// getReloadStatus returns the result of the last config (re)load
+func getReloadStatus() reloadStatus {
+	lastReloadMu.RLock()
+	defer lastReloadMu.RUnlock()
+	return lastReload
+}
+
+// serverStatus latest scrape result of a server
+type serverStatus struct {
+	// LastScrape time the data of the server was last fetched
+	LastScrape time.Time
+	// LastSuccess time the data of the server was last fetched successfully
+	LastSuccess time.Time
+	// LastError error of the last fetch, nil if it succeeded
+	LastError error
+	// Degraded whether the connection served a reduced set of data
+	Degraded bool
+	// Snapshot data of the last successful fetch
+	Snapshot *collector.ServerSnapshot
+}
+
+// recordServerStatuses records the result of fetching the data of the servers,
+// servers no longer fetched are forgotten
+func recordServerStatuses(snapshots map[string]*collector.ServerSnapshot, errs map[string]error, now time.Time) {
+	serverStatusesMu.Lock()
+	defer serverStatusesMu.Unlock()
+
+	statuses := make(map[string]*serverStatus, len(snapshots)+len(errs))
+	for server, err := range errs {
+		status := &serverStatus{}
+		if old, ok := serverStatuses[server]; ok {
+			*status = *old
+		}
+		status.LastScrape = now
+		status.LastError = err
+		statuses[server] = status
+	}
+	for server, snapshot := range snapshots {
+		status := &serverStatus{
+			LastScrape:  now,
+			LastSuccess: now,
+			Snapshot:    snapshot,
+		}
+		if conn, ok := snapshot.Conn.(connections.Degradable); ok {
+			status.Degraded = conn.Degraded()
+		}
+		statuses[server] = status
+	}
+	serverStatuses = statuses
+}
+
+// getServerStatus returns the latest scrape result of the server
+func getServerStatus(server string) (serverStatus, bool) {
+	serverStatusesMu.RLock()
+	defer serverStatusesMu.RUnlock()
+
+	status, ok := serverStatuses[server]
+	if !ok {
+		return serverStatus{}, false
+	}
+	return *status, true
+}
+
+//go:embed status.html
+var statusPageTemplate string
+
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"ago": func(t time.Time) string {
+		return time.Since(t).Truncate(time.Second).String() + " ago"
+	},
+	"join": strings.Join,
+}).Parse(statusPageTemplate))
+
+// statusPageData data of the status page
+type statusPageData struct {
+	Version     string
+	MetricsPath string
+	Reload      reloadStatus
+	Servers     []statusPageServer
+}
+
+// statusPageServer a server on the status page
+type statusPageServer struct {
+	Name    string
+	Address string
+	Mode    config.QueryMode
+	// Scraped whether the server was scraped since it was added
+	Scraped    bool
+	Status     serverStatus
+	Collectors []string
+}
+
+// State returns the connection state of the server
+func (s statusPageServer) State() string {
+	switch {
+	case !s.Scraped:
+		return "unknown"
+	case s.Status.LastError != nil:
+		return "down"
+	case s.Status.Degraded:
+		return "degraded"
+	default:
+		return "up"
+	}
+}
+
+// statusPageHandler renders the status page with the servers, their latest
+// scrape results and the config reload status
+func statusPageHandler(w http.ResponseWriter, r *http.Request) {
+	servers := cc.servers()
+	collectors := srcdsCollector.serverCollectors()
+
+	data := statusPageData{
+		Version:     version.Info(),
+		MetricsPath: opts.metricsPath,
+		Reload:      getReloadStatus(),
+		Servers:     make([]statusPageServer, 0, len(servers)),
+	}
+	for name, server := range servers {
+		status, scraped := getServerStatus(server.Address)
+		data.Servers = append(data.Servers, statusPageServer{
+			Name:       name,
+			Address:    server.Address,
+			Mode:       server.Mode,
+			Scraped:    scraped,
+			Status:     status,
+			Collectors: collectors[server.Address],
+		})
+	}
+	sort.Slice(data.Servers, func(i, j int) bool {
+		return data.Servers[i].Name < data.Servers[j].Name
+	})
+
+	var buf bytes.Buffer
+	if err := statusPage.Execute(&buf, data); err != nil {
+		log.Errorf("Failed to render status page: %s", err)
+		http.Error(w, "failed to render status page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
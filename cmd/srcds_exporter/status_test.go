@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/collector"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// withCollectors makes collectors the active collectors
+func withCollectors(t *testing.T, collectors map[string]*collectorInstance) {
+	old := srcdsCollector
+	srcdsCollector = NewSRCDSCollector(false, 0)
+	srcdsCollector.SetCollectors(collectors)
+	t.Cleanup(func() { srcdsCollector = old })
+}
+
+func TestStatusPage(t *testing.T) {
+	withServerStatuses(t, config.API{})
+	withCollectors(t, map[string]*collectorInstance{
+		"map": {settings: &collector.Settings{Servers: map[string]*yaml.Node{
+			"127.0.0.1:27015": nil,
+			"127.0.0.1:27016": nil,
+		}}},
+		"players": {settings: &collector.Settings{Servers: map[string]*yaml.Node{
+			"127.0.0.1:27015": nil,
+		}}},
+	})
+	t.Cleanup(func() { recordReload(nil, time.Time{}) })
+
+	tests := []struct {
+		name     string
+		reload   error
+		expected []string
+	}{
+		{
+			name: "loaded",
+			expected: []string{
+				"Loaded successfully",
+				`<td>server1<br><span class="muted">127.0.0.1:27015</span></td>`,
+				`<td class="up">up</td>`,
+				`<td>de_dust2</td>`,
+				`<td>2 / 24</td>`,
+				`<td>map, players</td>`,
+				`<td>server2<br><span class="muted">127.0.0.1:27016</span></td>`,
+				`<td>A2S</td>`,
+				`<td class="down">down</td>`,
+				`<span class="error">i/o timeout</span>`,
+				`<td>map</td>`,
+			},
+		},
+		{
+			name:   "reload failed",
+			reload: errors.New("invalid <config>"),
+			expected: []string{
+				"Last reload failed",
+				"invalid &lt;config&gt;",
+				"is still active",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recordReload(nil, time.Now().Add(-time.Minute))
+			if test.reload != nil {
+				recordReload(test.reload, time.Now())
+			}
+
+			rec := httptest.NewRecorder()
+			newHTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+			for _, expected := range test.expected {
+				assert.Contains(t, rec.Body.String(), expected)
+			}
+		})
+	}
+}
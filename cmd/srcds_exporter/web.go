@@ -0,0 +1,76 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/exporter-toolkit/web"
+	"github.com/sirupsen/logrus"
+)
+
+// newHTTPHandler returns the handler with all endpoints of the exporter
+func newHTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(opts.metricsPath, scrapeHandler)
+
+	// Enable reload endpoint only when enabled by the flag
+	if opts.reloadEndpointEnabled {
+		mux.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				fmt.Fprintf(w, "This endpoint requires a POST request.\n")
+				return
+			}
+
+			rc := make(chan error)
+			reloadCh <- rc
+			if err := <-rc; err != nil {
+				http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+			}
+		})
+	}
+	mux.Handle("/api/", newAPIHandler())
+
+	mux.HandleFunc("/", statusPageHandler)
+
+	return mux
+}
+
+// webFlagConfig returns the exporter-toolkit web config from the flags
+func webFlagConfig() *web.FlagConfig {
+	return &web.FlagConfig{
+		WebListenAddresses: &[]string{opts.metricsAddr},
+		WebSystemdSocket:   &opts.webSystemdSocket,
+		WebConfigFile:      &opts.webConfigFile,
+	}
+}
+
+// newSlogLogger returns a slog logger writing to the logrus output, as the
+// exporter-toolkit requires a slog logger
+func newSlogLogger() *slog.Logger {
+	level := slog.LevelInfo
+	if log.IsLevelEnabled(logrus.DebugLevel) {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(log.Out, &slog.HandlerOptions{
+		Level: level,
+	}))
+}
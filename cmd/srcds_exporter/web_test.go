@@ -0,0 +1,292 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/prometheus/exporter-toolkit/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// generateCert creates a certificate signed by parent, or self-signed if parent is nil
+func generateCert(t *testing.T, dir string, name string, parent *testCert, isCA bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return c
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.cert.Raw},
+		PrivateKey:  c.key,
+	}
+}
+
+// startWebServer serves the exporter handler with the given web config and returns its address
+func startWebServer(t *testing.T, webConfig string) string {
+	withConfig(t, &config.Config{})
+	withCollectors(t, map[string]*collectorInstance{})
+
+	dir := t.TempDir()
+	webConfigFile := filepath.Join(dir, "web-config.yml")
+	require.NoError(t, os.WriteFile(webConfigFile, []byte(webConfig), 0o600))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &http.Server{
+		Handler: newHTTPHandler(),
+	}
+	flagConfig := webFlagConfig()
+	flagConfig.WebConfigFile = &webConfigFile
+	go web.Serve(l, server, flagConfig, newSlogLogger())
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	return l.Addr().String()
+}
+
+func TestWebConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil, true)
+	serverCert := generateCert(t, dir, "server", ca, false)
+	clientCert := generateCert(t, dir, "client", ca, false)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+
+	tests := []struct {
+		name       string
+		webConfig  string
+		clientCert bool
+		user       string
+		password   string
+		http2      bool
+		expected   int
+		errOkay    bool
+	}{
+		{
+			name: "TLS",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+`, serverCert.certFile, serverCert.keyFile),
+			expected: http.StatusOK,
+		},
+		{
+			name: "basic auth without credentials",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+basic_auth_users:
+  prometheus: %s
+`, serverCert.certFile, serverCert.keyFile, hash),
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "basic auth with wrong password",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+basic_auth_users:
+  prometheus: %s
+`, serverCert.certFile, serverCert.keyFile, hash),
+			user:     "prometheus",
+			password: "wrong",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "basic auth with credentials",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+basic_auth_users:
+  prometheus: %s
+`, serverCert.certFile, serverCert.keyFile, hash),
+			user:     "prometheus",
+			password: "secret",
+			expected: http.StatusOK,
+		},
+		{
+			name: "client cert required but missing",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+  client_auth_type: RequireAndVerifyClientCert
+  client_ca_file: %s
+`, serverCert.certFile, serverCert.keyFile, ca.certFile),
+			errOkay: true,
+		},
+		{
+			name: "client cert required",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+  client_auth_type: RequireAndVerifyClientCert
+  client_ca_file: %s
+`, serverCert.certFile, serverCert.keyFile, ca.certFile),
+			clientCert: true,
+			expected:   http.StatusOK,
+		},
+		{
+			name: "HTTP/2",
+			webConfig: fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+http_server_config:
+  http2: true
+`, serverCert.certFile, serverCert.keyFile),
+			http2:    true,
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startWebServer(t, tt.webConfig)
+
+			tlsConfig := &tls.Config{
+				RootCAs: rootCAs,
+			}
+			if tt.clientCert {
+				tlsConfig.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+			}
+			client := &http.Client{
+				Timeout: 5 * time.Second,
+				Transport: &http.Transport{
+					TLSClientConfig:   tlsConfig,
+					ForceAttemptHTTP2: tt.http2,
+				},
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "https://"+addr+"/", nil)
+			require.NoError(t, err)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+
+			var resp *http.Response
+			// The server is started in the background, retry until it accepts connections
+			require.Eventually(t, func() bool {
+				resp, err = client.Do(req)
+				if err == nil || tt.errOkay {
+					return true
+				}
+				return false
+			}, 5*time.Second, 50*time.Millisecond)
+
+			if tt.errOkay {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			io.Copy(io.Discard, resp.Body)
+
+			assert.Equal(t, tt.expected, resp.StatusCode)
+			if tt.http2 {
+				assert.Equal(t, 2, resp.ProtoMajor)
+			}
+		})
+	}
+}
+
+func TestWebConfigPlainHTTPRejected(t *testing.T) {
+	dir := t.TempDir()
+	ca := generateCert(t, dir, "ca", nil, true)
+	serverCert := generateCert(t, dir, "server", ca, false)
+
+	addr := startWebServer(t, fmt.Sprintf(`tls_server_config:
+  cert_file: %s
+  key_file: %s
+`, serverCert.certFile, serverCert.keyFile))
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	var resp *http.Response
+	var err error
+	require.Eventually(t, func() bool {
+		resp, err = client.Get("http://" + addr + "/")
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	defer resp.Body.Close()
+
+	// Go's TLS server answers plain HTTP requests with a 400
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -17,25 +17,115 @@ limitations under the License.
 package collector
 
 import (
+	"sync"
+	"sync/atomic"
+
 	"github.com/galexrt/srcds_exporter/connector"
 	"github.com/prometheus/client_golang/prometheus"
+	yaml "gopkg.in/yaml.v3"
 )
 
 // Namespace metric namespace name
 const Namespace = "srcds"
 
+// DefaultMaxConcurrency default amount of servers queried at the same time
+const DefaultMaxConcurrency = 10
+
 // Factories contains the list of all available collectors.
-var Factories = make(map[string]func() (Collector, error))
+var Factories = make(map[string]func(settings *Settings) (Collector, error))
 
-var cons *connector.Connector
+var (
+	cons *connector.Connector
+	// maxConcurrency amount of servers queried at the same time, set on
+	// start and reload while scrapes read it, 0 for DefaultMaxConcurrency
+	maxConcurrency atomic.Int32
+
+	serverLabels      = map[string]map[string]string{}
+	serverLabelsMutex sync.RWMutex
+)
 
 // Collector is the interface a collector has to implement.
 type Collector interface {
-	// Get new metrics and expose them via prometheus registry.
-	Update(ch chan<- prometheus.Metric) error
+	// Get new metrics from the server snapshots of the current scrape and
+	// expose them via prometheus registry.
+	Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error
+}
+
+// CommandCollector is implemented by collectors which run RCON commands on
+// the servers. The commands are run while fetching the snapshots, within the
+// scrape timeout and the limit of servers queried at the same time, their
+// outputs are returned by ServerSnapshot.RunCommand.
+type CommandCollector interface {
+	Collector
+	// Commands returns the commands to run on the server
+	Commands(server string) []string
+}
+
+// Settings holds the config a collector is created with
+type Settings struct {
+	// Options the collector's block from the `collectors` section of the config, may be nil
+	Options *yaml.Node
+	// Servers the collector is enabled for keyed by server label, with the
+	// collector's block from the server's `collectorOptions` (may be nil)
+	Servers map[string]*yaml.Node
+}
+
+// DecodeOptions decodes the collector options for the given server into out.
+// The server's options are decoded over the global options.
+func (s *Settings) DecodeOptions(server string, out interface{}) error {
+	if s.Options != nil {
+		if err := s.Options.Decode(out); err != nil {
+			return err
+		}
+	}
+	if node := s.Servers[server]; node != nil {
+		if err := node.Decode(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter returns the snapshots of the servers the collector is enabled for
+func (s *Settings) Filter(snapshots map[string]*ServerSnapshot) map[string]*ServerSnapshot {
+	out := make(map[string]*ServerSnapshot, len(s.Servers))
+	for server, snapshot := range snapshots {
+		if _, ok := s.Servers[server]; ok {
+			out[server] = snapshot
+		}
+	}
+	return out
 }
 
 // SetConnector a given connector for the collectors
 func SetConnector(con *connector.Connector) {
 	cons = con
 }
+
+// SetServerLabels sets the static labels per server (label value) added to all metrics of the server
+func SetServerLabels(labels map[string]map[string]string) {
+	serverLabelsMutex.Lock()
+	defer serverLabelsMutex.Unlock()
+	serverLabels = labels
+}
+
+func getServerLabels(server string) map[string]string {
+	serverLabelsMutex.RLock()
+	defer serverLabelsMutex.RUnlock()
+	return serverLabels[server]
+}
+
+// SetMaxConcurrency set the amount of servers queried at the same time
+func SetMaxConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	maxConcurrency.Store(int32(n))
+}
+
+func getMaxConcurrency() int {
+	if n := maxConcurrency.Load(); n > 0 {
+		return int(n)
+	}
+	return DefaultMaxConcurrency
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// countingConn connection counting the calls made to the server
+type countingConn struct {
+	snapshots atomic.Int32
+	queries   atomic.Int32
+	commands  atomic.Int32
+}
+
+func (c *countingConn) Reconnect() error { return nil }
+
+func (c *countingConn) Close() {}
+
+func (c *countingConn) GetMap() (string, error) {
+	c.queries.Add(1)
+	return "de_dust2", nil
+}
+
+func (c *countingConn) GetPlayerCount() (*models.PlayerCount, error) {
+	c.queries.Add(1)
+	return &models.PlayerCount{}, nil
+}
+
+func (c *countingConn) GetPlayers() (map[string]*models.Player, error) {
+	c.queries.Add(1)
+	return nil, nil
+}
+
+func (c *countingConn) GetSnapshot() (*models.Snapshot, error) {
+	c.snapshots.Add(1)
+	return &models.Snapshot{
+		Status: models.Status{
+			Map:         "de_dust2",
+			PlayerCount: models.PlayerCount{Current: 1, Max: 10, Humans: 1},
+		},
+		Players: map[string]*models.Player{
+			"STEAM_1:0:1": {Username: "Alice", SteamID: "STEAM_1:0:1"},
+		},
+	}, nil
+}
+
+func (c *countingConn) RunCommand(cmd string) (string, error) {
+	c.commands.Add(1)
+	return "", nil
+}
+
+func TestSnapshotsSharedByCollectors(t *testing.T) {
+	conns := map[string]*countingConn{
+		"127.0.0.1:27015": {},
+		"127.0.0.1:27016": {},
+	}
+	iconns := map[string]connections.IConnection{}
+	servers := map[string]*yaml.Node{}
+	for addr, conn := range conns {
+		iconns[addr] = conn
+		servers[addr] = nil
+	}
+	withConnections(t, iconns)
+
+	var collectors []Collector
+	for _, name := range []string{"playercount", "map", "players", "sourcemod", "metamod"} {
+		c, err := Factories[name](&Settings{Servers: servers})
+		require.NoError(t, err)
+		collectors = append(collectors, c)
+	}
+	commands := map[string][]string{}
+	for server := range servers {
+		for _, c := range collectors {
+			if cc, ok := c.(CommandCollector); ok {
+				commands[server] = append(commands[server], cc.Commands(server)...)
+			}
+		}
+	}
+
+	// One scrape: a single fetch per server, shared by all collectors
+	snapshots, errs := FetchSnapshots(context.Background(), commands)
+	require.Empty(t, errs)
+	ch := make(chan prometheus.Metric, 1000)
+	for _, c := range collectors {
+		c.Update(snapshots, ch)
+	}
+	close(ch)
+	assert.NotEmpty(t, ch)
+
+	for addr, conn := range conns {
+		assert.Equal(t, int32(1), conn.snapshots.Load(), addr)
+		assert.Equal(t, int32(0), conn.queries.Load(), addr)
+		// sm version, sm plugins list, sm exts list, meta version, meta list
+		assert.Equal(t, int32(5), conn.commands.Load(), addr)
+	}
+}
+
+func TestSettingsDecodeOptions(t *testing.T) {
+	type options struct {
+		Interval int    `yaml:"interval"`
+		Name     string `yaml:"name"`
+	}
+	node := func(content string) *yaml.Node {
+		n := &yaml.Node{}
+		require.NoError(t, yaml.Unmarshal([]byte(content), n))
+		return n
+	}
+
+	settings := &Settings{
+		Options: node("interval: 10\nname: global"),
+		Servers: map[string]*yaml.Node{
+			"127.0.0.1:27015": nil,
+			"127.0.0.1:27016": node("name: server2"),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		server string
+		want   options
+	}{
+		{name: "global options", server: "127.0.0.1:27015", want: options{Interval: 10, Name: "global"}},
+		{name: "server override", server: "127.0.0.1:27016", want: options{Interval: 10, Name: "server2"}},
+		{name: "unknown server", server: "127.0.0.1:27017", want: options{Interval: 10, Name: "global"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := options{}
+			require.NoError(t, settings.DecodeOptions(tt.server, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Error(t, (&Settings{Options: node("interval: nope")}).DecodeOptions("127.0.0.1:27015", &options{}))
+}
+
+func TestSettingsFilter(t *testing.T) {
+	snapshots := map[string]*ServerSnapshot{
+		"127.0.0.1:27015": {Server: "127.0.0.1:27015"},
+		"127.0.0.1:27016": {Server: "127.0.0.1:27016"},
+	}
+	settings := &Settings{
+		Servers: map[string]*yaml.Node{
+			"127.0.0.1:27016": nil,
+			"127.0.0.1:27017": nil,
+		},
+	}
+
+	got := settings.Filter(snapshots)
+	assert.Len(t, got, 1)
+	assert.Same(t, snapshots["127.0.0.1:27016"], got["127.0.0.1:27016"])
+}
@@ -0,0 +1,414 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// customDefaultHelp help of custom metrics without one, the same for all
+	// commands so a metric scraped from several servers has one help
+	customDefaultHelp = "Custom metric extracted from the output of a RCON command."
+	// customReservedPrefix prefix of the built-in metrics, custom metrics may
+	// only use it with customPrefix
+	customReservedPrefix = "srcds_"
+	customPrefix         = "srcds_custom_"
+)
+
+var (
+	metricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	jsonPathRegex   = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\[(\d+)\]|\["([^"]+)"\])`)
+)
+
+// customOptions options of the custom collector
+type customOptions struct {
+	Commands []customCommand `yaml:"commands"`
+}
+
+// customCommand a RCON command and how a metric is extracted from its output
+type customCommand struct {
+	Command string `yaml:"command"`
+	// Regex the value is taken from the `value` group (or the first group if
+	// there is none), other named groups are added as labels
+	Regex string `yaml:"regex"`
+	// JSONPath path of the value in JSON output, e.g., `$.teams[0].score`
+	JSONPath string            `yaml:"jsonPath"`
+	Metric   string            `yaml:"metric"`
+	Help     string            `yaml:"help"`
+	Type     string            `yaml:"type"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+// customMetric a validated customCommand
+type customMetric struct {
+	customCommand
+	regex     *regexp.Regexp
+	valueType prometheus.ValueType
+}
+
+// customSample a value extracted from a command output with its labels
+type customSample struct {
+	Labels prometheus.Labels
+	Value  float64
+}
+
+type customCollector struct {
+	metrics map[string][]*customMetric
+}
+
+func init() {
+	Factories["custom"] = NewCustomCollector
+}
+
+// NewCustomCollector returns a new Collector exposing metrics extracted from
+// the output of configured RCON commands. A metric configured for several
+// servers or commands must have the same type, help and label names.
+func NewCustomCollector(settings *Settings) (Collector, error) {
+	metrics := make(map[string][]*customMetric, len(settings.Servers))
+	defined := map[string]*customMetric{}
+	for _, server := range slices.Sorted(maps.Keys(settings.Servers)) {
+		opts := &customOptions{}
+		if err := settings.DecodeOptions(server, opts); err != nil {
+			return nil, fmt.Errorf("server %s: %w", server, err)
+		}
+		for i, cmd := range opts.Commands {
+			metric, err := newCustomMetric(cmd)
+			if err != nil {
+				return nil, fmt.Errorf("server %s: custom command %d: %w", server, i, err)
+			}
+			if other, ok := defined[metric.Metric]; ok {
+				if err := other.compatible(metric); err != nil {
+					return nil, fmt.Errorf("server %s: custom command %d: %w", server, i, err)
+				}
+			} else {
+				defined[metric.Metric] = metric
+			}
+			metrics[server] = append(metrics[server], metric)
+		}
+	}
+
+	return &customCollector{
+		metrics: metrics,
+	}, nil
+}
+
+// newCustomMetric validates the command and compiles its regex
+func newCustomMetric(cmd customCommand) (*customMetric, error) {
+	if cmd.Command == "" {
+		return nil, errors.New("command is required")
+	}
+	if !metricNameRegex.MatchString(cmd.Metric) {
+		return nil, fmt.Errorf("invalid metric name %q", cmd.Metric)
+	}
+	if strings.HasPrefix(cmd.Metric, customReservedPrefix) && !strings.HasPrefix(cmd.Metric, customPrefix) {
+		return nil, fmt.Errorf("metric name %q is reserved for built-in metrics, use the %s prefix", cmd.Metric, customPrefix)
+	}
+	if (cmd.Regex == "") == (cmd.JSONPath == "") {
+		return nil, errors.New("exactly one of regex or jsonPath is required")
+	}
+	for label := range cmd.Labels {
+		if err := config.ValidateMetricLabelName(label); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.Help == "" {
+		cmd.Help = customDefaultHelp
+	}
+
+	metric := &customMetric{customCommand: cmd}
+	switch strings.ToLower(cmd.Type) {
+	case "", "gauge":
+		metric.valueType = prometheus.GaugeValue
+	case "counter":
+		metric.valueType = prometheus.CounterValue
+	case "untyped":
+		metric.valueType = prometheus.UntypedValue
+	default:
+		return nil, fmt.Errorf("unknown type %q, must be one of gauge, counter, untyped", cmd.Type)
+	}
+
+	if cmd.Regex != "" {
+		regex, err := regexp.Compile(cmd.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		if regex.NumSubexp() == 0 {
+			return nil, errors.New("regex must have a group for the value")
+		}
+		for _, name := range regex.SubexpNames() {
+			if name == "" || name == "value" {
+				continue
+			}
+			if err := config.ValidateMetricLabelName(name); err != nil {
+				return nil, fmt.Errorf("regex group: %w", err)
+			}
+		}
+		metric.regex = regex
+	} else if _, err := parseJSONPath(cmd.JSONPath); err != nil {
+		return nil, err
+	}
+
+	return metric, nil
+}
+
+// labelNames returns the sorted label names of the metric's samples
+func (m *customMetric) labelNames() []string {
+	names := slices.Collect(maps.Keys(m.Labels))
+	if m.regex != nil {
+		valueIndex := m.valueIndex()
+		for i, name := range m.regex.SubexpNames() {
+			if name != "" && i != valueIndex {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// valueIndex returns the regex group of the value, the `value` group or the
+// first group if there is none
+func (m *customMetric) valueIndex() int {
+	if i := m.regex.SubexpIndex("value"); i > 0 {
+		return i
+	}
+	return 1
+}
+
+// compatible returns an error when the metrics of the same name can't be
+// exported together
+func (m *customMetric) compatible(other *customMetric) error {
+	switch {
+	case m.valueType != other.valueType:
+		return fmt.Errorf("metric %q: type differs from another command of the metric", other.Metric)
+	case m.Help != other.Help:
+		return fmt.Errorf("metric %q: help differs from another command of the metric", other.Metric)
+	case !slices.Equal(m.labelNames(), other.labelNames()):
+		return fmt.Errorf("metric %q: labels %v differ from labels %v of another command of the metric", other.Metric, other.labelNames(), m.labelNames())
+	}
+	return nil
+}
+
+// Commands implements the CommandCollector interface
+func (c *customCollector) Commands(server string) []string {
+	cmds := make([]string, 0, len(c.metrics[server]))
+	for _, metric := range c.metrics[server] {
+		cmds = append(cmds, metric.Command)
+	}
+	return cmds
+}
+
+func (c *customCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	var errs []error
+	for _, snapshot := range snapshots {
+		for _, metric := range c.metrics[snapshot.Server] {
+			// Commands shared by metrics only run once
+			resp, err := snapshot.RunCommand(metric.Command)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("server %s: %s: %w", snapshot.Server, metric.Metric, err))
+				continue
+			}
+			samples, err := metric.extract(resp)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("server %s: %s: %w", snapshot.Server, metric.Metric, err))
+				continue
+			}
+			for _, sample := range samples {
+				if label := snapshot.StaticLabelClash(sample.Labels); label != "" {
+					errs = append(errs, fmt.Errorf("server %s: %s: label %q clashes with a static label of the server", snapshot.Server, metric.Metric, label))
+					break
+				}
+				desc := prometheus.NewDesc(metric.Metric, metric.Help, nil, snapshot.Labels(sample.Labels))
+				ch <- prometheus.MustNewConstMetric(desc, metric.valueType, sample.Value)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// extract returns the samples found in the command output, label sets
+// matched more than once are only returned for the first match
+func (m *customMetric) extract(output string) ([]customSample, error) {
+	if m.regex == nil {
+		value, err := extractJSONPath(output, m.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		return []customSample{{Labels: m.labels(nil), Value: value}}, nil
+	}
+
+	valueIndex := m.valueIndex()
+	samples := []customSample{}
+	seen := map[string]struct{}{}
+	for _, match := range m.regex.FindAllStringSubmatch(output, -1) {
+		value, err := strconv.ParseFloat(strings.TrimSpace(match[valueIndex]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", match[valueIndex], err)
+		}
+		groups := map[string]string{}
+		for i, name := range m.regex.SubexpNames() {
+			if name != "" && i != valueIndex {
+				groups[name] = match[i]
+			}
+		}
+		labels := m.labels(groups)
+
+		key := labelsKey(labels)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		samples = append(samples, customSample{Labels: labels, Value: value})
+	}
+	return samples, nil
+}
+
+// labels returns the configured labels with the given labels added
+func (m *customMetric) labels(groups map[string]string) prometheus.Labels {
+	labels := make(prometheus.Labels, len(m.Labels)+len(groups))
+	for k, v := range m.Labels {
+		labels[k] = v
+	}
+	for k, v := range groups {
+		labels[k] = v
+	}
+	return labels
+}
+
+func labelsKey(labels prometheus.Labels) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// jsonPathElement a key or index of a JSON path
+type jsonPathElement struct {
+	Key   string
+	Index int
+}
+
+// parseJSONPath parses the JSONPath subset of keys and array indexes, e.g.,
+// `$.teams[0].score` or `$["key with spaces"]`
+func parseJSONPath(path string) ([]jsonPathElement, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid jsonPath %q, must start with $", path)
+	}
+	rest := path[1:]
+	elements := []jsonPathElement{}
+	for rest != "" {
+		match := jsonPathRegex.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("invalid jsonPath %q at %q", path, rest)
+		}
+		switch {
+		case match[1] != "":
+			elements = append(elements, jsonPathElement{Key: match[1], Index: -1})
+		case match[3] != "":
+			elements = append(elements, jsonPathElement{Key: match[3], Index: -1})
+		default:
+			index, _ := strconv.Atoi(match[2])
+			elements = append(elements, jsonPathElement{Index: index})
+		}
+		rest = rest[len(match[0]):]
+	}
+	return elements, nil
+}
+
+// extractJSONPath returns the number, bool (as 0 or 1) or numeric string at
+// the path of the JSON document in the output
+func extractJSONPath(output string, path string) (float64, error) {
+	elements, err := parseJSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	doc, err := findJSON(output)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, el := range elements {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			if el.Index >= 0 {
+				return 0, fmt.Errorf("%s: expected an array, got an object", path)
+			}
+			var ok bool
+			if doc, ok = v[el.Key]; !ok {
+				return 0, fmt.Errorf("%s: key %q not found", path, el.Key)
+			}
+		case []interface{}:
+			if el.Index < 0 {
+				return 0, fmt.Errorf("%s: expected an object, got an array", path)
+			}
+			if el.Index >= len(v) {
+				return 0, fmt.Errorf("%s: index %d out of range", path, el.Index)
+			}
+			doc = v[el.Index]
+		default:
+			return 0, fmt.Errorf("%s: can't descend into %T", path, doc)
+		}
+	}
+
+	switch v := doc.(type) {
+	case json.Number:
+		return v.Float64()
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		return 0, fmt.Errorf("%s: value of type %T isn't a number", path, doc)
+	}
+}
+
+// findJSON decodes the first JSON object or array in the output, text before
+// it is skipped as plugins often prefix their output, e.g., with `[SM]`
+func findJSON(output string) (interface{}, error) {
+	err := errors.New("no JSON found in output")
+	for i := 0; i < len(output); i++ {
+		if output[i] != '{' && output[i] != '[' {
+			continue
+		}
+		var doc interface{}
+		decoder := json.NewDecoder(strings.NewReader(output[i:]))
+		decoder.UseNumber()
+		decodeErr := decoder.Decode(&doc)
+		if decodeErr == nil {
+			return doc, nil
+		}
+		err = fmt.Errorf("invalid JSON in output: %w", decodeErr)
+	}
+	return nil, err
+}
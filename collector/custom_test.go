@@ -0,0 +1,344 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// fakeConn connection answering RCON commands with fixed outputs
+type fakeConn struct {
+	connections.IConnection
+	outputs map[string]string
+}
+
+func (c *fakeConn) RunCommand(cmd string) (string, error) {
+	return c.outputs[cmd], nil
+}
+
+// fetchCommands runs the commands of the collector on the snapshots, like
+// FetchSnapshots does
+func fetchCommands(c Collector, snapshots map[string]*ServerSnapshot) {
+	for server, snapshot := range snapshots {
+		snapshot.runCommands(context.Background(), c.(CommandCollector).Commands(server))
+	}
+}
+
+// collectorFunc adapts a Collector and snapshots to a prometheus.Collector
+type collectorFunc struct {
+	c         Collector
+	snapshots map[string]*ServerSnapshot
+}
+
+func (f collectorFunc) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(f, ch)
+}
+
+func (f collectorFunc) Collect(ch chan<- prometheus.Metric) {
+	f.c.Update(f.snapshots, ch)
+}
+
+const customConfig = `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+  - command: sm_teams
+    regex: '(?m)^(?P<team>\w+) score (?P<value>\d+)$'
+    metric: srcds_custom_team_score
+    type: counter
+    labels:
+      mode: zombies
+  - command: status_json
+    jsonPath: '$.round["time left"]'
+    metric: srcds_custom_round_time_left_seconds
+    help: Time left in the round.
+`
+
+func TestCustomCollector(t *testing.T) {
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(customConfig), &node))
+	c, err := NewCustomCollector(&Settings{
+		Options: node.Content[0],
+		Servers: map[string]*yaml.Node{"server1": nil},
+	})
+	require.NoError(t, err)
+
+	snapshots := map[string]*ServerSnapshot{
+		"server1": {
+			Server: "server1",
+			Conn: &fakeConn{outputs: map[string]string{
+				"sm_zombies":  "[SM] Zombies alive: 12\n",
+				"sm_teams":    "CT score 3\nT score 5\nCT score 4\n",
+				"status_json": `[SM] {"round": {"time left": 95.5, "number": 3}}`,
+			}},
+			Snapshot: &models.Snapshot{},
+		},
+	}
+	fetchCommands(c, snapshots)
+
+	expected := `# HELP sm_zombies_alive Custom metric extracted from the output of a RCON command.
+# TYPE sm_zombies_alive gauge
+sm_zombies_alive{server="server1"} 12
+# HELP srcds_custom_round_time_left_seconds Time left in the round.
+# TYPE srcds_custom_round_time_left_seconds gauge
+srcds_custom_round_time_left_seconds{server="server1"} 95.5
+# HELP srcds_custom_team_score Custom metric extracted from the output of a RCON command.
+# TYPE srcds_custom_team_score counter
+srcds_custom_team_score{mode="zombies",server="server1",team="CT"} 3
+srcds_custom_team_score{mode="zombies",server="server1",team="T"} 5
+`
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc{c: c, snapshots: snapshots}, strings.NewReader(expected)))
+}
+
+func TestCustomCollectorStaticLabelClash(t *testing.T) {
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(customConfig), &node))
+	c, err := NewCustomCollector(&Settings{
+		Options: node.Content[0],
+		Servers: map[string]*yaml.Node{"server1": nil},
+	})
+	require.NoError(t, err)
+
+	snapshots := map[string]*ServerSnapshot{
+		"server1": {
+			Server: "server1",
+			Conn: &fakeConn{outputs: map[string]string{
+				"sm_zombies":  "[SM] Zombies alive: 12\n",
+				"sm_teams":    "CT score 3\n",
+				"status_json": `{"round": {"time left": 95.5}}`,
+			}},
+			StaticLabels: map[string]string{"team": "red"},
+			Snapshot:     &models.Snapshot{},
+		},
+	}
+	fetchCommands(c, snapshots)
+
+	ch := make(chan prometheus.Metric, 10)
+	err = c.Update(snapshots, ch)
+	close(ch)
+	assert.EqualError(t, err, `server server1: srcds_custom_team_score: label "team" clashes with a static label of the server`)
+	names := []string{}
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	assert.Len(t, names, 2)
+	for _, name := range names {
+		assert.NotContains(t, name, "srcds_custom_team_score")
+	}
+}
+
+var newCustomMetricTests = []struct {
+	name string
+	cmd  customCommand
+	err  string
+}{
+	{
+		name: "missing command",
+		cmd:  customCommand{Regex: `(\d+)`, Metric: "foo"},
+		err:  "command is required",
+	},
+	{
+		name: "invalid metric name",
+		cmd:  customCommand{Command: "foo", Regex: `(\d+)`, Metric: "1foo"},
+		err:  `invalid metric name "1foo"`,
+	},
+	{
+		name: "reserved metric name",
+		cmd:  customCommand{Command: "foo", Regex: `(\d+)`, Metric: "srcds_playercount_current"},
+		err:  `metric name "srcds_playercount_current" is reserved for built-in metrics, use the srcds_custom_ prefix`,
+	},
+	{
+		name: "regex and jsonPath",
+		cmd:  customCommand{Command: "foo", Regex: `(\d+)`, JSONPath: "$.a", Metric: "foo"},
+		err:  "exactly one of regex or jsonPath is required",
+	},
+	{
+		name: "regex without group",
+		cmd:  customCommand{Command: "foo", Regex: `\d+`, Metric: "foo"},
+		err:  "regex must have a group for the value",
+	},
+	{
+		name: "reserved group name",
+		cmd:  customCommand{Command: "foo", Regex: `(?P<server>\w+) (\d+)`, Metric: "foo"},
+		err:  `regex group: label name "server" is reserved`,
+	},
+	{
+		name: "reserved label name",
+		cmd:  customCommand{Command: "foo", Regex: `(\d+)`, Metric: "foo", Labels: map[string]string{"job": "x"}},
+		err:  `label name "job" is reserved`,
+	},
+	{
+		name: "invalid jsonPath",
+		cmd:  customCommand{Command: "foo", JSONPath: "a.b", Metric: "foo"},
+		err:  `invalid jsonPath "a.b", must start with $`,
+	},
+	{
+		name: "unknown type",
+		cmd:  customCommand{Command: "foo", JSONPath: "$.a[1]", Metric: "foo", Type: "summary"},
+		err:  `unknown type "summary", must be one of gauge, counter, untyped`,
+	},
+}
+
+func TestNewCustomMetric(t *testing.T) {
+	for _, tt := range newCustomMetricTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCustomMetric(tt.cmd)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewCustomCollectorConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  string
+		server2 string
+		err     string
+	}{
+		{
+			name: "same metric on all servers",
+			global: `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+`,
+			server2: `commands:
+  - command: sm_zombies2
+    regex: 'Zombies: (?P<value>\d+)'
+    metric: sm_zombies_alive
+`,
+		},
+		{
+			name: "same metric from two commands",
+			global: `commands:
+  - command: sm_ct
+    regex: 'CT (?P<value>\d+)'
+    metric: sm_score
+    labels:
+      team: CT
+  - command: sm_t
+    regex: 'T (?P<value>\d+)'
+    metric: sm_score
+    labels:
+      team: T
+`,
+		},
+		{
+			name: "different type",
+			global: `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+`,
+			server2: `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+    type: counter
+`,
+			err: `server server2: custom command 0: metric "sm_zombies_alive": type differs from another command of the metric`,
+		},
+		{
+			name: "different help",
+			global: `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+`,
+			server2: `commands:
+  - command: sm_zombies
+    regex: 'Zombies alive: (\d+)'
+    metric: sm_zombies_alive
+    help: Zombies alive.
+`,
+			err: `server server2: custom command 0: metric "sm_zombies_alive": help differs from another command of the metric`,
+		},
+		{
+			name: "different labels",
+			global: `commands:
+  - command: sm_ct
+    regex: 'CT (?P<value>\d+)'
+    metric: sm_score
+    labels:
+      team: CT
+  - command: sm_t
+    regex: '(?P<team>T) (?P<round>\d+) (?P<value>\d+)'
+    metric: sm_score
+`,
+			err: `server server1: custom command 1: metric "sm_score": labels [round team] differ from labels [team] of another command of the metric`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &Settings{
+				Options: &yaml.Node{},
+				Servers: map[string]*yaml.Node{"server1": nil, "server2": nil},
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.global), settings.Options))
+			if tt.server2 != "" {
+				settings.Servers["server2"] = &yaml.Node{}
+				require.NoError(t, yaml.Unmarshal([]byte(tt.server2), settings.Servers["server2"]))
+			}
+
+			_, err := NewCustomCollector(settings)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+var extractJSONPathTests = []struct {
+	output   string
+	path     string
+	expected float64
+	errOkay  bool
+}{
+	{`{"a": {"b": [1, {"c": 2}]}}`, "$.a.b[1].c", 2, false},
+	{`{"alive": true}`, "$.alive", 1, false},
+	{`{"count": " 7 "}`, "$.count", 7, false},
+	{`[3, 4]`, "$[1]", 4, false},
+	{`{"a": 1}`, "$.b", 0, true},
+	{`{"a": [1]}`, "$.a[2]", 0, true},
+	{`{"a": "text"}`, "$.a", 0, true},
+	{`no json`, "$.a", 0, true},
+}
+
+func TestExtractJSONPath(t *testing.T) {
+	for _, tt := range extractJSONPathTests {
+		actual, err := extractJSONPath(tt.output, tt.path)
+		if tt.errOkay {
+			assert.Error(t, err, tt.path)
+			continue
+		}
+		require.NoError(t, err, tt.path)
+		assert.Equal(t, tt.expected, actual, tt.path)
+	}
+}
@@ -17,15 +17,174 @@ limitations under the License.
 package collector
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func getConnections() map[string]connections.IConnection {
-	con, err := cons.GetConnections()
-	if err != nil {
-		log.Fatal(err)
+// getConnections returns the connections to query, replaced in tests
+var getConnections = func() map[string]connections.IConnection {
+	return cons.GetConnections()
+}
+
+// ServerSnapshot is the data fetched from a server during a scrape, which
+// is shared by all collectors.
+type ServerSnapshot struct {
+	Server string
+	Conn   connections.IConnection
+	// StaticLabels labels from the server's config added to all metrics of the server
+	StaticLabels map[string]string
+	*models.Snapshot
+	// Duration it took to fetch the snapshot
+	Duration time.Duration
+
+	// outputs of the commands run for the CommandCollectors
+	outputs map[string]commandOutput
+}
+
+type commandOutput struct {
+	output string
+	err    error
+}
+
+// Labels returns the constant labels for a metric of the server: the server
+// label, the server's static labels and the given labels
+func (s *ServerSnapshot) Labels(labels prometheus.Labels) prometheus.Labels {
+	out := make(prometheus.Labels, len(s.StaticLabels)+len(labels)+1)
+	for k, v := range s.StaticLabels {
+		out[k] = v
+	}
+	for k, v := range labels {
+		out[k] = v
+	}
+	out["server"] = s.Server
+	return out
+}
+
+// StaticLabelClash returns the first of the given label names (sorted) which
+// is also a static label of the server, empty if there is none
+func (s *ServerSnapshot) StaticLabelClash(labels prometheus.Labels) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if _, ok := s.StaticLabels[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// RunCommand returns the output of a console command run on the server via
+// RCON while fetching the snapshot, see CommandCollector. It fails for
+// connection modes without RCON.
+func (s *ServerSnapshot) RunCommand(cmd string) (string, error) {
+	out, ok := s.outputs[cmd]
+	if !ok {
+		return "", fmt.Errorf("command %q wasn't run on server %s", cmd, s.Server)
+	}
+	return out.output, out.err
+}
+
+// runCommands runs the commands on the server one after another, the
+// commands left when ctx is done fail with its error
+func (s *ServerSnapshot) runCommands(ctx context.Context, cmds []string) {
+	s.outputs = make(map[string]commandOutput, len(cmds))
+	runner, canRun := s.Conn.(connections.CommandRunner)
+	for _, cmd := range cmds {
+		if _, ok := s.outputs[cmd]; ok {
+			continue
+		}
+		var out commandOutput
+		switch {
+		case !canRun:
+			out.err = fmt.Errorf("connection mode of server %s can't run RCON commands", s.Server)
+		case ctx.Err() != nil:
+			out.err = ctx.Err()
+		default:
+			out.output, out.err = runner.RunCommand(cmd)
+		}
+		s.outputs[cmd] = out
+	}
+}
+
+type snapshotResult struct {
+	snapshot *ServerSnapshot
+	err      error
+}
+
+// FetchSnapshots fetches a snapshot from every server and runs the server's
+// commands (see CommandCollector), querying at most maxConcurrency servers at
+// the same time. Only servers that answered before ctx is done are returned,
+// the others are reported in the returned errors map.
+func FetchSnapshots(ctx context.Context, commands map[string][]string) (map[string]*ServerSnapshot, map[string]error) {
+	conns := getConnections()
+
+	// Buffered so workers that finish after the deadline don't block forever
+	results := make(chan snapshotResult, len(conns))
+	sem := make(chan struct{}, getMaxConcurrency())
+	for server, con := range conns {
+		go func(server string, con connections.IConnection) {
+			res := snapshotResult{
+				snapshot: &ServerSnapshot{
+					Server:       server,
+					Conn:         con,
+					StaticLabels: getServerLabels(server),
+				},
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				res.err = ctx.Err()
+				results <- res
+				return
+			}
+			defer func() { <-sem }()
+
+			begin := time.Now()
+			res.snapshot.Snapshot, res.err = con.GetSnapshot()
+			if res.err == nil && res.snapshot.Snapshot == nil {
+				res.err = errors.New("no data returned by server")
+			}
+			if res.err == nil {
+				res.snapshot.runCommands(ctx, commands[server])
+			}
+			res.snapshot.Duration = time.Since(begin)
+			results <- res
+		}(server, con)
 	}
-	return con
+
+	snapshots := make(map[string]*ServerSnapshot, len(conns))
+	errs := map[string]error{}
+	for pending := len(conns); pending > 0; pending-- {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				errs[res.snapshot.Server] = res.err
+				continue
+			}
+			snapshots[res.snapshot.Server] = res.snapshot
+		case <-ctx.Done():
+			for server := range conns {
+				if _, ok := snapshots[server]; ok {
+					continue
+				}
+				if _, ok := errs[server]; ok {
+					continue
+				}
+				errs[server] = fmt.Errorf("scrape timed out waiting for server: %w", ctx.Err())
+			}
+			return snapshots, errs
+		}
+	}
+
+	return snapshots, errs
 }
@@ -0,0 +1,280 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/connector/connections"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConn connection whose GetSnapshot sleeps for delay, or blocks
+// until release is closed when delay is 0
+type blockingConn struct {
+	connections.IConnection
+	delay   time.Duration
+	release chan struct{}
+	err     error
+
+	running    *int32
+	maxRunning *int32
+}
+
+// track counts a running call and records the maximum of concurrent calls,
+// the returned func ends the call
+func track(running *int32, maxRunning *int32) func() {
+	if running == nil {
+		return func() {}
+	}
+	n := atomic.AddInt32(running, 1)
+	for {
+		max := atomic.LoadInt32(maxRunning)
+		if n <= max || atomic.CompareAndSwapInt32(maxRunning, max, n) {
+			break
+		}
+	}
+	return func() { atomic.AddInt32(running, -1) }
+}
+
+func (c *blockingConn) GetSnapshot() (*models.Snapshot, error) {
+	defer track(c.running, c.maxRunning)()
+	if c.delay > 0 {
+		time.Sleep(c.delay)
+	} else if c.release != nil {
+		<-c.release
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &models.Snapshot{}, nil
+}
+
+// commandConn connection answering RCON commands after delay
+type commandConn struct {
+	blockingConn
+	cmdDelay time.Duration
+}
+
+func (c *commandConn) RunCommand(cmd string) (string, error) {
+	defer track(c.running, c.maxRunning)()
+	time.Sleep(c.cmdDelay)
+	return "output of " + cmd, nil
+}
+
+func withConnections(t *testing.T, conns map[string]connections.IConnection) {
+	t.Helper()
+	orig := getConnections
+	getConnections = func() map[string]connections.IConnection {
+		return conns
+	}
+	t.Cleanup(func() { getConnections = orig })
+}
+
+func withMaxConcurrency(t *testing.T, n int) {
+	t.Helper()
+	orig := maxConcurrency.Load()
+	SetMaxConcurrency(n)
+	t.Cleanup(func() { maxConcurrency.Store(orig) })
+}
+
+func TestFetchSnapshotsConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConcurrency int
+		servers        int
+	}{
+		{name: "limit below server count", maxConcurrency: 3, servers: 12},
+		{name: "limit of one", maxConcurrency: 1, servers: 4},
+		{name: "limit above server count", maxConcurrency: 10, servers: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withMaxConcurrency(t, tt.maxConcurrency)
+			var running, maxRunning int32
+			conns := map[string]connections.IConnection{}
+			for i := 0; i < tt.servers; i++ {
+				conns[string(rune('a'+i))] = &blockingConn{
+					delay:      20 * time.Millisecond,
+					running:    &running,
+					maxRunning: &maxRunning,
+				}
+			}
+			withConnections(t, conns)
+
+			snapshots, errs := FetchSnapshots(context.Background(), nil)
+			assert.Empty(t, errs)
+			assert.Len(t, snapshots, tt.servers)
+			assert.LessOrEqual(t, int(maxRunning), tt.maxConcurrency)
+			assert.Equal(t, min(tt.maxConcurrency, tt.servers), int(maxRunning))
+		})
+	}
+}
+
+func TestFetchSnapshotsConcurrencyChange(t *testing.T) {
+	withMaxConcurrency(t, 2)
+	conns := map[string]connections.IConnection{}
+	for i := 0; i < 8; i++ {
+		conns[string(rune('a'+i))] = &blockingConn{delay: time.Millisecond}
+	}
+	withConnections(t, conns)
+
+	// The limit is changed, e.g., by a reload, while scrapes are running
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetMaxConcurrency(i%4 + 1)
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		snapshots, errs := FetchSnapshots(context.Background(), nil)
+		assert.Empty(t, errs)
+		assert.Len(t, snapshots, len(conns))
+	}
+	<-done
+}
+
+func TestFetchSnapshotsDeadline(t *testing.T) {
+	errRefused := errors.New("connection refused")
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name           string
+		maxConcurrency int
+		conns          map[string]connections.IConnection
+		wantSnapshots  []string
+		wantErrs       map[string]error
+	}{
+		{
+			name:           "blocked server times out",
+			maxConcurrency: 10,
+			conns: map[string]connections.IConnection{
+				"fast":    &blockingConn{},
+				"blocked": &blockingConn{release: release},
+			},
+			wantSnapshots: []string{"fast"},
+			wantErrs: map[string]error{
+				"blocked": context.DeadlineExceeded,
+			},
+		},
+		{
+			name:           "server waiting for a worker times out",
+			maxConcurrency: 1,
+			conns: map[string]connections.IConnection{
+				"blocked1": &blockingConn{release: release},
+				"blocked2": &blockingConn{release: release},
+			},
+			wantSnapshots: []string{},
+			wantErrs: map[string]error{
+				"blocked1": context.DeadlineExceeded,
+				"blocked2": context.DeadlineExceeded,
+			},
+		},
+		{
+			name:           "failed server is reported",
+			maxConcurrency: 10,
+			conns: map[string]connections.IConnection{
+				"fast":    &blockingConn{},
+				"failing": &blockingConn{err: errRefused},
+				"blocked": &blockingConn{release: release},
+			},
+			wantSnapshots: []string{"fast"},
+			wantErrs: map[string]error{
+				"failing": errRefused,
+				"blocked": context.DeadlineExceeded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withMaxConcurrency(t, tt.maxConcurrency)
+			withConnections(t, tt.conns)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			begin := time.Now()
+			snapshots, errs := FetchSnapshots(ctx, nil)
+			assert.Less(t, time.Since(begin), time.Second)
+
+			got := []string{}
+			for server := range snapshots {
+				got = append(got, server)
+			}
+			assert.ElementsMatch(t, tt.wantSnapshots, got)
+			require.Len(t, errs, len(tt.wantErrs))
+			for server, want := range tt.wantErrs {
+				require.Contains(t, errs, server)
+				assert.ErrorIs(t, errs[server], want)
+			}
+		})
+	}
+}
+
+func TestFetchSnapshotsCommands(t *testing.T) {
+	withMaxConcurrency(t, 2)
+	var running, maxRunning int32
+	conns := map[string]connections.IConnection{
+		"query": &blockingConn{},
+	}
+	commands := map[string][]string{
+		"query": {"sm version"},
+	}
+	for _, server := range []string{"rcon1", "rcon2", "rcon3", "rcon4"} {
+		conns[server] = &commandConn{
+			blockingConn: blockingConn{running: &running, maxRunning: &maxRunning},
+			cmdDelay:     10 * time.Millisecond,
+		}
+		commands[server] = []string{"sm version", "meta version", "sm version"}
+	}
+	withConnections(t, conns)
+
+	snapshots, errs := FetchSnapshots(context.Background(), commands)
+	require.Empty(t, errs)
+	require.Len(t, snapshots, 5)
+	// Commands are run by the workers, within the concurrency limit
+	assert.Equal(t, int32(2), maxRunning)
+
+	out, err := snapshots["rcon1"].RunCommand("meta version")
+	require.NoError(t, err)
+	assert.Equal(t, "output of meta version", out)
+	_, err = snapshots["rcon1"].RunCommand("meta list")
+	assert.EqualError(t, err, `command "meta list" wasn't run on server rcon1`)
+	_, err = snapshots["query"].RunCommand("sm version")
+	assert.EqualError(t, err, "connection mode of server query can't run RCON commands")
+}
+
+func TestRunCommandsDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	snapshot := &ServerSnapshot{Server: "server1", Conn: &commandConn{}}
+	snapshot.runCommands(ctx, []string{"sm version", "meta version"})
+	for _, cmd := range []string{"sm version", "meta version"} {
+		_, err := snapshot.RunCommand(cmd)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
@@ -28,32 +28,81 @@ func init() {
 	Factories["map"] = NewMapCollector
 }
 
-// NewMapCollector returns a new Collector exposing the current map.
-func NewMapCollector() (Collector, error) {
+// NewMapCollector returns a new Collector exposing the current map and the
+// map history recorded by RecordMapHistory.
+func NewMapCollector(settings *Settings) (Collector, error) {
 	current := []*prometheus.Desc{}
 	return &mapCollector{
 		current: current,
 	}, nil
 }
 
-func (c *mapCollector) Update(ch chan<- prometheus.Metric) error {
-	for server, con := range getConnections() {
-		mapName, err := con.GetMap()
-		if err != nil {
-			return err
-		}
+func (c *mapCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	for _, snapshot := range snapshots {
+		mapName := snapshot.Status.Map
 		if mapName == "" {
 			continue
 		}
 		current := prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, "", "map"),
 			"The current map on the server.",
-			nil, prometheus.Labels{
-				"server": server,
-				"map":    mapName,
-			})
+			nil, snapshot.Labels(prometheus.Labels{
+				"map": mapName,
+			}))
 		ch <- prometheus.MustNewConstMetric(
 			current, prometheus.GaugeValue, float64(1))
+
+		c.updateHistory(snapshot, ch)
 	}
 	return nil
 }
+
+// updateHistory exposes the map changes and per map statistics of the server
+func (c *mapCollector) updateHistory(snapshot *ServerSnapshot, ch chan<- prometheus.Metric) {
+	history, ok := mapHistory.get(snapshot.Server)
+	if !ok {
+		return
+	}
+
+	changes := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "map", "changes_total"),
+		"The count of map changes seen on the server.",
+		nil, snapshot.Labels(nil))
+	ch <- prometheus.MustNewConstMetric(
+		changes, prometheus.CounterValue, float64(history.Changes))
+	start := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "map", "start_timestamp_seconds"),
+		"The time the current map was first seen on the server.",
+		nil, snapshot.Labels(prometheus.Labels{
+			"map": history.Map,
+		}))
+	ch <- prometheus.MustNewConstMetric(
+		start, prometheus.GaugeValue, float64(history.Start.UnixNano())/1e9)
+
+	for name, stats := range history.Maps {
+		played := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "map", "played_seconds_total"),
+			"The time the map was played on the server.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"map": name,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			played, prometheus.CounterValue, stats.PlayedSeconds)
+		playerSeconds := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "map", "player_seconds_total"),
+			"The player count integrated over the time the map was played on the server.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"map": name,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			playerSeconds, prometheus.CounterValue, stats.PlayerSeconds)
+		average := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "map", "average_players"),
+			"The average player count while the map was played on the server.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"map": name,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			average, prometheus.GaugeValue, stats.AveragePlayers())
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/state"
+)
+
+// mapHistoryMaxGap time between two observations of a server up to which
+// the time in between is counted as played, longer gaps (exporter restarts,
+// unreachable servers) aren't counted
+var mapHistoryMaxGap = 10 * time.Minute
+
+// mapHistorySaveInterval interval the play time of a server is persisted in
+// while its map doesn't change, map changes are persisted right away
+var mapHistorySaveInterval = 5 * time.Minute
+
+var mapHistory = &mapHistoryTracker{
+	servers: map[string]*serverMapHistory{},
+}
+
+// serverMapHistory the current map and per map statistics of a server
+type serverMapHistory struct {
+	Map      string    `json:"map"`
+	Start    time.Time `json:"start"`
+	LastSeen time.Time `json:"lastSeen"`
+	Changes  uint64    `json:"changes"`
+	// Maps statistics per map name
+	Maps map[string]*mapStats `json:"maps"`
+
+	// saved time of the last observation persisted in the store
+	saved time.Time
+}
+
+// mapStats statistics of a map played on a server
+type mapStats struct {
+	// PlayedSeconds time the map was played
+	PlayedSeconds float64 `json:"playedSeconds"`
+	// PlayerSeconds player count integrated over the played time
+	PlayerSeconds float64 `json:"playerSeconds"`
+}
+
+// AveragePlayers returns the average player count while the map was played
+func (s *mapStats) AveragePlayers() float64 {
+	if s.PlayedSeconds == 0 {
+		return 0
+	}
+	return s.PlayerSeconds / s.PlayedSeconds
+}
+
+// mapHistoryBucket bucket of the state store the map history is persisted in,
+// keyed by server
+const mapHistoryBucket = "mapHistory"
+
+// mapHistoryTracker tracks map changes and play time of the servers across
+// scrapes, optionally persisted in the state store
+type mapHistoryTracker struct {
+	mu      sync.Mutex
+	store   *state.Store
+	servers map[string]*serverMapHistory
+}
+
+// setStore sets the store the history is persisted in and loads the history
+// saved in it, the history is kept in memory only without store
+func (t *mapHistoryTracker) setStore(store *state.Store) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+	if store == nil {
+		return nil
+	}
+
+	servers := map[string]*serverMapHistory{}
+	err := store.View(func(tx *state.Tx) error {
+		return tx.ForEach(mapHistoryBucket, func(server string, value []byte) error {
+			h := &serverMapHistory{}
+			if err := json.Unmarshal(value, h); err != nil {
+				return fmt.Errorf("failed to parse map history of %s: %w", server, err)
+			}
+			if h.Maps == nil {
+				h.Maps = map[string]*mapStats{}
+			}
+			servers[server] = h
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	t.servers = servers
+	return nil
+}
+
+// RecordMapHistory records the maps of the snapshots in the map history, it is
+// called for every scrape independent of the enabled collectors. The history
+// is persisted when a map changed and otherwise every mapHistorySaveInterval.
+func RecordMapHistory(snapshots map[string]*ServerSnapshot) error {
+	var changed []string
+	for server, snapshot := range snapshots {
+		if snapshot.Status.Map == "" {
+			continue
+		}
+		if mapHistory.observe(server, snapshot.Status.Map, snapshot.Status.PlayerCount.Current, snapshot.Time) {
+			changed = append(changed, server)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return mapHistory.save(changed)
+}
+
+// observe records that the server was on the map with the given player count
+// at the given time, it returns true when the history needs to be saved
+func (t *mapHistoryTracker) observe(server string, mapName string, players int, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.servers[server]
+	if !ok {
+		h = &serverMapHistory{
+			Map:   mapName,
+			Start: now,
+			Maps:  map[string]*mapStats{},
+		}
+		t.servers[server] = h
+	}
+
+	changed := h.saved.IsZero()
+	if h.Map != mapName {
+		h.Map = mapName
+		h.Start = now
+		h.Changes++
+		changed = true
+	} else if gap := now.Sub(h.LastSeen); !h.LastSeen.IsZero() && gap > 0 && gap <= mapHistoryMaxGap {
+		stats, ok := h.Maps[mapName]
+		if !ok {
+			stats = &mapStats{}
+			h.Maps[mapName] = stats
+		}
+		stats.PlayedSeconds += gap.Seconds()
+		stats.PlayerSeconds += gap.Seconds() * float64(players)
+	}
+	h.LastSeen = now
+	return changed || now.Sub(h.saved) >= mapHistorySaveInterval
+}
+
+// get returns a copy of the server's history
+func (t *mapHistoryTracker) get(server string) (serverMapHistory, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.servers[server]
+	if !ok {
+		return serverMapHistory{}, false
+	}
+	out := *h
+	out.Maps = make(map[string]*mapStats, len(h.Maps))
+	for name, stats := range h.Maps {
+		s := *stats
+		out.Maps[name] = &s
+	}
+	return out, true
+}
+
+// save persists the history of the servers in the store, if one is set
+func (t *mapHistoryTracker) save(servers []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.store == nil {
+		return nil
+	}
+
+	return t.store.Update(func(tx *state.Tx) error {
+		for _, server := range servers {
+			if h, ok := t.servers[server]; ok {
+				if err := tx.Put(mapHistoryBucket, server, h); err != nil {
+					return err
+				}
+				h.saved = h.LastSeen
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/galexrt/srcds_exporter/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapObservation a server seen on a map, after the start of the test
+type mapObservation struct {
+	mapName string
+	players int
+	after   time.Duration
+}
+
+func TestMapHistoryObserve(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		observations    []mapObservation
+		expectedMap     string
+		expectedStart   time.Duration
+		expectedChanges uint64
+		expectedStats   map[string]mapStats
+	}{
+		{
+			name: "same map",
+			observations: []mapObservation{
+				{"de_dust2", 10, 0},
+				{"de_dust2", 10, time.Minute},
+				{"de_dust2", 20, 2 * time.Minute},
+			},
+			expectedMap: "de_dust2",
+			expectedStats: map[string]mapStats{
+				"de_dust2": {PlayedSeconds: 120, PlayerSeconds: 1800},
+			},
+		},
+		{
+			name: "map change",
+			observations: []mapObservation{
+				{"de_dust2", 10, 0},
+				{"de_dust2", 10, time.Minute},
+				{"de_inferno", 4, 2 * time.Minute},
+				{"de_inferno", 6, 3 * time.Minute},
+			},
+			expectedMap:     "de_inferno",
+			expectedStart:   2 * time.Minute,
+			expectedChanges: 1,
+			expectedStats: map[string]mapStats{
+				"de_dust2":   {PlayedSeconds: 60, PlayerSeconds: 600},
+				"de_inferno": {PlayedSeconds: 60, PlayerSeconds: 360},
+			},
+		},
+		{
+			name: "gap not counted",
+			observations: []mapObservation{
+				{"de_dust2", 10, 0},
+				{"de_dust2", 10, time.Hour},
+				{"de_dust2", 10, time.Hour + time.Minute},
+			},
+			expectedMap: "de_dust2",
+			expectedStats: map[string]mapStats{
+				"de_dust2": {PlayedSeconds: 60, PlayerSeconds: 600},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := &mapHistoryTracker{servers: map[string]*serverMapHistory{}}
+			for _, o := range test.observations {
+				tracker.observe("server", o.mapName, o.players, start.Add(o.after))
+			}
+
+			history, ok := tracker.get("server")
+			require.True(t, ok)
+			assert.Equal(t, test.expectedMap, history.Map)
+			assert.Equal(t, start.Add(test.expectedStart), history.Start)
+			assert.Equal(t, test.expectedChanges, history.Changes)
+			stats := map[string]mapStats{}
+			for name, s := range history.Maps {
+				stats[name] = *s
+			}
+			assert.Equal(t, test.expectedStats, stats)
+		})
+	}
+}
+
+func TestMapHistoryPersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.db")
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := state.Open(file)
+	require.NoError(t, err)
+	tracker := &mapHistoryTracker{servers: map[string]*serverMapHistory{}}
+	require.NoError(t, tracker.setStore(store))
+	tracker.observe("server", "de_dust2", 8, start)
+	tracker.observe("server", "de_dust2", 8, start.Add(30*time.Second))
+	require.NoError(t, tracker.save([]string{"server"}))
+	require.NoError(t, store.Close())
+
+	store, err = state.Open(file)
+	require.NoError(t, err)
+	defer store.Close()
+	restarted := &mapHistoryTracker{servers: map[string]*serverMapHistory{}}
+	require.NoError(t, restarted.setStore(store))
+	history, ok := restarted.get("server")
+	require.True(t, ok)
+	assert.Equal(t, "de_dust2", history.Map)
+	assert.True(t, start.Equal(history.Start))
+	assert.Equal(t, 8.0, history.Maps["de_dust2"].AveragePlayers())
+
+	// Play time continues to be counted after the restart
+	restarted.observe("server", "de_dust2", 8, start.Add(time.Minute))
+	history, _ = restarted.get("server")
+	assert.Equal(t, 60.0, history.Maps["de_dust2"].PlayedSeconds)
+}
+
+func TestRecordMapHistory(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	oldHistory := mapHistory
+	mapHistory = &mapHistoryTracker{servers: map[string]*serverMapHistory{}}
+	t.Cleanup(func() { mapHistory = oldHistory })
+	require.NoError(t, mapHistory.setStore(store))
+
+	// No map collector is created, the history is recorded from the snapshots
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		mapName       string
+		after         time.Duration
+		expectedSaved time.Duration
+	}{
+		{name: "first observation saved", mapName: "de_dust2", after: 0, expectedSaved: 0},
+		{name: "same map not saved", mapName: "de_dust2", after: time.Minute, expectedSaved: 0},
+		{name: "no map ignored", mapName: "", after: 90 * time.Second, expectedSaved: 0},
+		{name: "map change saved", mapName: "de_inferno", after: 2 * time.Minute, expectedSaved: 2 * time.Minute},
+		{name: "same map not saved again", mapName: "de_inferno", after: 4 * time.Minute, expectedSaved: 2 * time.Minute},
+		{name: "saved after the interval", mapName: "de_inferno", after: 7 * time.Minute, expectedSaved: 7 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots := map[string]*ServerSnapshot{
+				"server": {
+					Server: "server",
+					Snapshot: &models.Snapshot{
+						Time:   start.Add(tt.after),
+						Status: models.Status{Map: tt.mapName},
+					},
+				},
+			}
+			require.NoError(t, RecordMapHistory(snapshots))
+
+			var saved serverMapHistory
+			require.NoError(t, store.View(func(tx *state.Tx) error {
+				ok, err := tx.Get(mapHistoryBucket, "server", &saved)
+				assert.True(t, ok)
+				return err
+			}))
+			assert.True(t, start.Add(tt.expectedSaved).Equal(saved.LastSeen), "saved %s", saved.LastSeen)
+		})
+	}
+
+	history, ok := mapHistory.get("server")
+	require.True(t, ok)
+	assert.Equal(t, "de_inferno", history.Map)
+	assert.Equal(t, uint64(1), history.Changes)
+	assert.Equal(t, 60.0, history.Maps["de_dust2"].PlayedSeconds)
+	assert.Equal(t, 300.0, history.Maps["de_inferno"].PlayedSeconds)
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/galexrt/srcds_exporter/parser"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Match phases
+const (
+	MatchPhaseUnknown  = "unknown"
+	MatchPhaseWarmup   = "warmup"
+	MatchPhaseLive     = "live"
+	MatchPhaseGameOver = "gameover"
+)
+
+var (
+	matchStates      = map[string]*matchState{}
+	matchStatesMutex sync.Mutex
+)
+
+// matchState team and round state of a server built from its log events
+type matchState struct {
+	Map     string
+	Phase   string
+	Round   int
+	Scores  map[string]int
+	Players map[string]int
+}
+
+func newMatchState(mapName string) *matchState {
+	return &matchState{
+		Map:     mapName,
+		Phase:   MatchPhaseUnknown,
+		Scores:  map[string]int{},
+		Players: map[string]int{},
+	}
+}
+
+// apply updates the state with the event
+func (s *matchState) apply(event *models.MatchEvent) {
+	switch event.Type {
+	case models.TeamScoreEvent:
+		s.Scores[event.Team] = event.Score
+		s.Players[event.Team] = event.Players
+	case models.RoundEndEvent:
+		for team, score := range event.Scores {
+			s.Scores[team] = score
+		}
+	case models.RoundStartEvent:
+		// Derived from the scores if known, so a lost log packet doesn't skew it
+		if len(s.Scores) > 0 {
+			s.Round = 1
+			for _, score := range s.Scores {
+				s.Round += score
+			}
+		} else {
+			s.Round++
+		}
+		if s.Phase == MatchPhaseUnknown || s.Phase == MatchPhaseGameOver {
+			s.Phase = MatchPhaseLive
+		}
+	case models.RoundRestartEvent, models.MatchStartEvent:
+		for team := range s.Scores {
+			s.Scores[team] = 0
+		}
+		s.Round = 0
+		if event.Type == models.MatchStartEvent {
+			s.Phase = MatchPhaseLive
+		}
+	case models.WarmupStartEvent:
+		s.Phase = MatchPhaseWarmup
+	case models.WarmupEndEvent:
+		s.Phase = MatchPhaseLive
+	case models.GameOverEvent:
+		s.Phase = MatchPhaseGameOver
+	case models.MapStartEvent:
+		*s = *newMatchState(event.Map)
+	}
+}
+
+// HandleLogMessage updates the match state of the server from a log
+// message, it is used as the logs.Handler of the log receiver
+func HandleLogMessage(server string, message string) {
+	event, ok := parser.ParseMatchEvent(message)
+	if !ok {
+		return
+	}
+
+	matchStatesMutex.Lock()
+	defer matchStatesMutex.Unlock()
+	state, ok := matchStates[server]
+	if !ok {
+		state = newMatchState("")
+		matchStates[server] = state
+	}
+	state.apply(event)
+}
+
+// getMatchState returns a copy of the server's match state, the state is
+// reset when the server is on another map than the logs said
+func getMatchState(server string, mapName string) (matchState, bool) {
+	matchStatesMutex.Lock()
+	defer matchStatesMutex.Unlock()
+	state, ok := matchStates[server]
+	if !ok {
+		return matchState{}, false
+	}
+	if mapName != "" && state.Map != mapName {
+		if state.Map != "" {
+			state.apply(&models.MatchEvent{Type: models.MapStartEvent, Map: mapName})
+		} else {
+			state.Map = mapName
+		}
+	}
+
+	out := *state
+	out.Scores = make(map[string]int, len(state.Scores))
+	for team, score := range state.Scores {
+		out.Scores[team] = score
+	}
+	out.Players = make(map[string]int, len(state.Players))
+	for team, players := range state.Players {
+		out.Players[team] = players
+	}
+	return out, true
+}
+
+type matchCollector struct{}
+
+func init() {
+	Factories["match"] = NewMatchCollector
+}
+
+// NewMatchCollector returns a new Collector exposing team scores, player
+// counts, the round and match phase from the server logs and the `mp_*`
+// cvars from the server rules. The `status` output has no team columns, so
+// the team state always comes from the logs. Only the A2S and hybrid modes
+// query the rules, in the RCON modes no cvars are exported.
+func NewMatchCollector(settings *Settings) (Collector, error) {
+	return &matchCollector{}, nil
+}
+
+func (c *matchCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	for _, snapshot := range snapshots {
+		for name, value := range snapshot.Rules {
+			if !strings.HasPrefix(name, "mp_") {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			cvar := prometheus.NewDesc(
+				prometheus.BuildFQName(Namespace, "match", "cvar"),
+				"The value of a numeric mp_* cvar from the server rules.",
+				nil, snapshot.Labels(prometheus.Labels{
+					"cvar": name,
+				}))
+			ch <- prometheus.MustNewConstMetric(
+				cvar, prometheus.GaugeValue, v)
+		}
+
+		state, ok := getMatchState(snapshot.Server, snapshot.Status.Map)
+		if !ok {
+			continue
+		}
+		for team, score := range state.Scores {
+			teamScore := prometheus.NewDesc(
+				prometheus.BuildFQName(Namespace, "team", "score"),
+				"The current score of the team.",
+				nil, snapshot.Labels(prometheus.Labels{
+					"team": team,
+				}))
+			ch <- prometheus.MustNewConstMetric(
+				teamScore, prometheus.GaugeValue, float64(score))
+		}
+		for team, players := range state.Players {
+			teamPlayers := prometheus.NewDesc(
+				prometheus.BuildFQName(Namespace, "team", "players"),
+				"The count of players in the team as of the last team score event.",
+				nil, snapshot.Labels(prometheus.Labels{
+					"team": team,
+				}))
+			ch <- prometheus.MustNewConstMetric(
+				teamPlayers, prometheus.GaugeValue, float64(players))
+		}
+		round := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "match", "round"),
+			"The current round of the match, 0 before the first round started.",
+			nil, snapshot.Labels(nil))
+		ch <- prometheus.MustNewConstMetric(
+			round, prometheus.GaugeValue, float64(state.Round))
+		phase := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "match", "phase"),
+			"The current phase of the match (unknown, warmup, live or gameover).",
+			nil, snapshot.Labels(prometheus.Labels{
+				"phase": state.Phase,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			phase, prometheus.GaugeValue, float64(1))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCollector(t *testing.T) {
+	defer func() { matchStates = map[string]*matchState{} }()
+
+	for _, message := range []string{
+		`Started map "de_inferno" (CRC "-123456")`,
+		`World triggered "Warmup_Start"`,
+		`World triggered "Match_Start" on "de_inferno"`,
+		`World triggered "Round_Start"`,
+		`Team "CT" triggered "SFUI_Notice_CTs_Win" (CT "1") (T "0")`,
+		`Team "CT" scored "1" with "5" players`,
+		`Team "TERRORIST" scored "0" with "4" players`,
+		`World triggered "Round_End"`,
+		`World triggered "Round_Start"`,
+		`"Player<2><STEAM_1:0:1><CT>" say "hello"`,
+	} {
+		HandleLogMessage("127.0.0.1:27015", message)
+	}
+
+	c, err := NewMatchCollector(&Settings{})
+	require.NoError(t, err)
+	snapshots := map[string]*ServerSnapshot{
+		"127.0.0.1:27015": {
+			Server: "127.0.0.1:27015",
+			Snapshot: &models.Snapshot{
+				Status: models.Status{Map: "de_inferno"},
+				Rules:  map[string]string{"mp_maxrounds": "30", "mp_teamname_1": "Team A", "sv_cheats": "0"},
+			},
+		},
+	}
+
+	expected := `# HELP srcds_match_cvar The value of a numeric mp_* cvar from the server rules.
+# TYPE srcds_match_cvar gauge
+srcds_match_cvar{cvar="mp_maxrounds",server="127.0.0.1:27015"} 30
+# HELP srcds_match_phase The current phase of the match (unknown, warmup, live or gameover).
+# TYPE srcds_match_phase gauge
+srcds_match_phase{phase="live",server="127.0.0.1:27015"} 1
+# HELP srcds_match_round The current round of the match, 0 before the first round started.
+# TYPE srcds_match_round gauge
+srcds_match_round{server="127.0.0.1:27015"} 2
+# HELP srcds_team_players The count of players in the team as of the last team score event.
+# TYPE srcds_team_players gauge
+srcds_team_players{server="127.0.0.1:27015",team="CT"} 5
+srcds_team_players{server="127.0.0.1:27015",team="TERRORIST"} 4
+# HELP srcds_team_score The current score of the team.
+# TYPE srcds_team_score gauge
+srcds_team_score{server="127.0.0.1:27015",team="CT"} 1
+srcds_team_score{server="127.0.0.1:27015",team="TERRORIST"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc{c: c, snapshots: snapshots}, strings.NewReader(expected)))
+
+	// The server changed the map without the log saying so
+	state, ok := getMatchState("127.0.0.1:27015", "de_nuke")
+	require.True(t, ok)
+	assert.Equal(t, MatchPhaseUnknown, state.Phase)
+	assert.Equal(t, 0, state.Round)
+	assert.Empty(t, state.Scores)
+}
+
+func TestMatchCollectorRCON(t *testing.T) {
+	defer func() { matchStates = map[string]*matchState{} }()
+
+	for _, message := range []string{
+		`Started map "de_inferno" (CRC "-123456")`,
+		`World triggered "Round_Start"`,
+		`Team "CT" scored "0" with "1" players`,
+		`Team "TERRORIST" scored "1" with "1" players`,
+	} {
+		HandleLogMessage("127.0.0.1:27015", message)
+	}
+
+	c, err := NewMatchCollector(&Settings{})
+	require.NoError(t, err)
+	// RCON modes have no rules and the status players have no team
+	players := map[string]*models.Player{
+		"STEAM_1:0:1": {Username: "Alice", SteamID: "STEAM_1:0:1"},
+		"STEAM_1:0:2": {Username: "Bob", SteamID: "STEAM_1:0:2"},
+	}
+	snapshots := map[string]*ServerSnapshot{
+		"127.0.0.1:27015": {
+			Server: "127.0.0.1:27015",
+			Snapshot: &models.Snapshot{
+				Status:  models.Status{Map: "de_inferno"},
+				Players: players,
+			},
+		},
+		// Without logs
+		"127.0.0.1:27016": {
+			Server: "127.0.0.1:27016",
+			Snapshot: &models.Snapshot{
+				Status:  models.Status{Map: "de_dust2"},
+				Players: players,
+			},
+		},
+	}
+
+	expected := `# HELP srcds_match_phase The current phase of the match (unknown, warmup, live or gameover).
+# TYPE srcds_match_phase gauge
+srcds_match_phase{phase="live",server="127.0.0.1:27015"} 1
+# HELP srcds_match_round The current round of the match, 0 before the first round started.
+# TYPE srcds_match_round gauge
+srcds_match_round{server="127.0.0.1:27015"} 1
+# HELP srcds_team_players The count of players in the team as of the last team score event.
+# TYPE srcds_team_players gauge
+srcds_team_players{server="127.0.0.1:27015",team="CT"} 1
+srcds_team_players{server="127.0.0.1:27015",team="TERRORIST"} 1
+# HELP srcds_team_score The current score of the team.
+# TYPE srcds_team_score gauge
+srcds_team_score{server="127.0.0.1:27015",team="CT"} 0
+srcds_team_score{server="127.0.0.1:27015",team="TERRORIST"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc{c: c, snapshots: snapshots}, strings.NewReader(expected)))
+}
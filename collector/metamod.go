@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/galexrt/srcds_exporter/parser"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metamodCollector struct{}
+
+func init() {
+	Factories["metamod"] = NewMetamodCollector
+}
+
+// NewMetamodCollector returns a new Collector exposing the Metamod:Source
+// version and plugins.
+func NewMetamodCollector(settings *Settings) (Collector, error) {
+	return &metamodCollector{}, nil
+}
+
+// Commands implements the CommandCollector interface
+func (c *metamodCollector) Commands(server string) []string {
+	return []string{"meta version", "meta list"}
+}
+
+func (c *metamodCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	var errs []error
+	for _, snapshot := range snapshots {
+		if err := c.updateServer(snapshot, ch); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", snapshot.Server, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *metamodCollector) updateServer(snapshot *ServerSnapshot, ch chan<- prometheus.Metric) error {
+	resp, err := snapshot.RunCommand("meta version")
+	if err != nil {
+		return err
+	}
+	version := parser.ParseMetamodVersion(resp)
+	if version == "" {
+		return errors.New("Metamod:Source isn't loaded, no version in `meta version` output")
+	}
+	info := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "metamod", "info"),
+		"The Metamod:Source version loaded on the server.",
+		nil, snapshot.Labels(prometheus.Labels{
+			"version": version,
+		}))
+	ch <- prometheus.MustNewConstMetric(
+		info, prometheus.GaugeValue, float64(1))
+
+	resp, err = snapshot.RunCommand("meta list")
+	if err != nil {
+		return err
+	}
+	// Plugins can share a name, each label set may only be sent once
+	seen := map[string]struct{}{}
+	for _, plugin := range parser.ParseMetamodPlugins(resp) {
+		key := plugin.Name + "\x00" + plugin.Version + "\x00" + plugin.Status
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		pluginInfo := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "metamod", "plugin_info"),
+			"A Metamod:Source plugin on the server with its version and status.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"name":    plugin.Name,
+				"version": plugin.Version,
+				"status":  plugin.Status,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			pluginInfo, prometheus.GaugeValue, float64(1))
+	}
+
+	return nil
+}
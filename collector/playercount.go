@@ -30,10 +30,10 @@ func init() {
 }
 
 // NewPlayerCountCollector returns a new Collector exposing the current map.
-func NewPlayerCountCollector() (Collector, error) {
+func NewPlayerCountCollector(settings *Settings) (Collector, error) {
 	current := []*prometheus.Desc{}
 	limit := []*prometheus.Desc{}
-	for server := range getConnections() {
+	for server := range settings.Servers {
 		current = append(current, prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, "playercount", "current"),
 			"The current player count of the server.",
@@ -53,28 +53,18 @@ func NewPlayerCountCollector() (Collector, error) {
 	}, nil
 }
 
-func (c *playerCountCollector) Update(ch chan<- prometheus.Metric) error {
-	for server, con := range getConnections() {
-		playerCount, err := con.GetPlayerCount()
-		if err != nil {
-			return err
-		}
-		if playerCount == nil {
-			return nil
-		}
+func (c *playerCountCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	for _, snapshot := range snapshots {
+		playerCount := snapshot.Status.PlayerCount
 
 		current := prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, "playercount", "current"),
 			"The current count players on the server.",
-			nil, prometheus.Labels{
-				"server": server,
-			})
+			nil, snapshot.Labels(nil))
 		limit := prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, "playercount", "limit"),
 			"The limit of players on the server.",
-			nil, prometheus.Labels{
-				"server": server,
-			})
+			nil, snapshot.Labels(nil))
 		ch <- prometheus.MustNewConstMetric(
 			current, prometheus.GaugeValue, float64(playerCount.Current))
 		ch <- prometheus.MustNewConstMetric(
@@ -84,9 +74,7 @@ func (c *playerCountCollector) Update(ch chan<- prometheus.Metric) error {
 			humans := prometheus.NewDesc(
 				prometheus.BuildFQName(Namespace, "playercount", "humans"),
 				"The current count of humans players on the server.",
-				nil, prometheus.Labels{
-					"server": server,
-				})
+				nil, snapshot.Labels(nil))
 			ch <- prometheus.MustNewConstMetric(
 				humans, prometheus.GaugeValue, float64(playerCount.Humans))
 		}
@@ -95,9 +83,7 @@ func (c *playerCountCollector) Update(ch chan<- prometheus.Metric) error {
 			bots := prometheus.NewDesc(
 				prometheus.BuildFQName(Namespace, "playercount", "bots"),
 				"The current count of bot players on the server.",
-				nil, prometheus.Labels{
-					"server": server,
-				})
+				nil, snapshot.Labels(nil))
 			ch <- prometheus.MustNewConstMetric(
 				bots, prometheus.GaugeValue, float64(playerCount.Bots))
 		}
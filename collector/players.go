@@ -31,11 +31,11 @@ func init() {
 }
 
 // NewPlayersCollector returns a new Collector exposing the current players.
-func NewPlayersCollector() (Collector, error) {
+func NewPlayersCollector(settings *Settings) (Collector, error) {
 	list := []*prometheus.Desc{}
 	ping := []*prometheus.Desc{}
 	loss := []*prometheus.Desc{}
-	for server := range getConnections() {
+	for server := range settings.Servers {
 		list = append(list, prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, "players", "online"),
 			"The current players on the server.",
@@ -62,35 +62,32 @@ func NewPlayersCollector() (Collector, error) {
 	}, nil
 }
 
-func (c *playersCollector) Update(ch chan<- prometheus.Metric) error {
-	for server, con := range getConnections() {
-		players, err := con.GetPlayers()
-		if err != nil {
-			return err
-		}
-
-		for _, player := range players {
+func (c *playersCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	for _, snapshot := range snapshots {
+		for _, player := range snapshot.Players {
+			// Players of modes without SteamIDs (e.g., A2S) are keyed by
+			// name, they would all end up in the same series
+			if player.SteamID == "" {
+				continue
+			}
 			list := prometheus.NewDesc(
 				prometheus.BuildFQName(Namespace, "players", "online"),
 				"The current players on the server.",
-				nil, prometheus.Labels{
-					"server":  server,
+				nil, snapshot.Labels(prometheus.Labels{
 					"steamid": player.SteamID,
-				})
+				}))
 			ping := prometheus.NewDesc(
 				prometheus.BuildFQName(Namespace, "players", "ping"),
 				"The current players ping on the server.",
-				nil, prometheus.Labels{
-					"server":  server,
+				nil, snapshot.Labels(prometheus.Labels{
 					"steamid": player.SteamID,
-				})
+				}))
 			loss := prometheus.NewDesc(
 				prometheus.BuildFQName(Namespace, "players", "loss"),
 				"The current players loss on the server.",
-				nil, prometheus.Labels{
-					"server":  server,
+				nil, snapshot.Labels(prometheus.Labels{
 					"steamid": player.SteamID,
-				})
+				}))
 			ch <- prometheus.MustNewConstMetric(
 				list, prometheus.GaugeValue, float64(1))
 			ch <- prometheus.MustNewConstMetric(
@@ -0,0 +1,61 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestPlayersCollector(t *testing.T) {
+	c, err := NewPlayersCollector(&Settings{
+		Servers: map[string]*yaml.Node{"server1": nil},
+	})
+	require.NoError(t, err)
+
+	snapshots := map[string]*ServerSnapshot{
+		"server1": {
+			Server: "server1",
+			Snapshot: &models.Snapshot{
+				Players: map[string]*models.Player{
+					"STEAM_1:0:1234": {Username: "Alice", SteamID: "STEAM_1:0:1234", Ping: 30, Loss: 1},
+					// Players of A2S based modes have no SteamID
+					"Bob":  {Username: "Bob"},
+					"Carl": {Username: "Carl"},
+				},
+			},
+		},
+	}
+
+	expected := `# HELP srcds_players_loss The current players loss on the server.
+# TYPE srcds_players_loss gauge
+srcds_players_loss{server="server1",steamid="STEAM_1:0:1234"} 1
+# HELP srcds_players_online The current players on the server.
+# TYPE srcds_players_online gauge
+srcds_players_online{server="server1",steamid="STEAM_1:0:1234"} 1
+# HELP srcds_players_ping The current players ping on the server.
+# TYPE srcds_players_ping gauge
+srcds_players_ping{server="server1",steamid="STEAM_1:0:1234"} 30
+`
+	assert.NoError(t, testutil.CollectAndCompare(collectorFunc{c: c, snapshots: snapshots}, strings.NewReader(expected)))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/galexrt/srcds_exporter/parser"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sourceModCollector struct{}
+
+func init() {
+	Factories["sourcemod"] = NewSourceModCollector
+}
+
+// NewSourceModCollector returns a new Collector exposing the SourceMod
+// version, plugins and extensions.
+func NewSourceModCollector(settings *Settings) (Collector, error) {
+	return &sourceModCollector{}, nil
+}
+
+// Commands implements the CommandCollector interface
+func (c *sourceModCollector) Commands(server string) []string {
+	return []string{"sm version", "sm plugins list", "sm exts list"}
+}
+
+func (c *sourceModCollector) Update(snapshots map[string]*ServerSnapshot, ch chan<- prometheus.Metric) error {
+	var errs []error
+	for _, snapshot := range snapshots {
+		if err := c.updateServer(snapshot, ch); err != nil {
+			errs = append(errs, fmt.Errorf("server %s: %w", snapshot.Server, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *sourceModCollector) updateServer(snapshot *ServerSnapshot, ch chan<- prometheus.Metric) error {
+	resp, err := snapshot.RunCommand("sm version")
+	if err != nil {
+		return err
+	}
+	version := parser.ParseSourceModVersion(resp)
+	if version == "" {
+		return errors.New("SourceMod isn't loaded, no version in `sm version` output")
+	}
+	info := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sourcemod", "info"),
+		"The SourceMod version loaded on the server.",
+		nil, snapshot.Labels(prometheus.Labels{
+			"version": version,
+		}))
+	ch <- prometheus.MustNewConstMetric(
+		info, prometheus.GaugeValue, float64(1))
+
+	resp, err = snapshot.RunCommand("sm plugins list")
+	if err != nil {
+		return err
+	}
+	failed := 0
+	// Plugins can share a name, each label set may only be sent once
+	seen := map[string]struct{}{}
+	for _, plugin := range parser.ParseSourceModPlugins(resp) {
+		if plugin.Failed() {
+			failed++
+		}
+		key := plugin.Name + "\x00" + plugin.Version + "\x00" + plugin.Status
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		pluginInfo := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "sourcemod", "plugin_info"),
+			"A SourceMod plugin on the server with its version and status.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"name":    plugin.Name,
+				"version": plugin.Version,
+				"status":  plugin.Status,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			pluginInfo, prometheus.GaugeValue, float64(1))
+	}
+	pluginsFailed := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sourcemod", "plugins_failed"),
+		"The count of SourceMod plugins which failed to load or errored.",
+		nil, snapshot.Labels(nil))
+	ch <- prometheus.MustNewConstMetric(
+		pluginsFailed, prometheus.GaugeValue, float64(failed))
+
+	resp, err = snapshot.RunCommand("sm exts list")
+	if err != nil {
+		return err
+	}
+	failed = 0
+	seen = map[string]struct{}{}
+	for _, ext := range parser.ParseSourceModExtensions(resp) {
+		if ext.Failed() {
+			failed++
+		}
+		key := ext.Name + "\x00" + ext.Version + "\x00" + ext.Status
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		extInfo := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "sourcemod", "extension_info"),
+			"A SourceMod extension on the server with its version and status.",
+			nil, snapshot.Labels(prometheus.Labels{
+				"name":    ext.Name,
+				"version": ext.Version,
+				"status":  ext.Status,
+			}))
+		ch <- prometheus.MustNewConstMetric(
+			extInfo, prometheus.GaugeValue, float64(1))
+	}
+	extsFailed := prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sourcemod", "extensions_failed"),
+		"The count of SourceMod extensions which failed to load.",
+		nil, snapshot.Labels(nil))
+	ch <- prometheus.MustNewConstMetric(
+		extsFailed, prometheus.GaugeValue, float64(failed))
+
+	return nil
+}
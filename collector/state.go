@@ -0,0 +1,27 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"github.com/galexrt/srcds_exporter/state"
+)
+
+// SetStateStore sets the store collectors persist their counters and history
+// in and loads the persisted data, nil keeps them in memory only
+func SetStateStore(store *state.Store) error {
+	return mapHistory.setStore(store)
+}
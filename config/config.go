@@ -18,12 +18,43 @@ package config
 
 import (
 	"time"
+
+	yaml "gopkg.in/yaml.v3"
 )
 
+// DefaultOptions options used for unset options in the config file
+var DefaultOptions = Options{
+	ConnectTimeout:       5 * time.Second,
+	CacheExpiration:      20 * time.Second,
+	CacheCleanupInterval: 12 * time.Second,
+
+	ServerFilesRefreshInterval: 5 * time.Minute,
+	StateCompactionInterval:    24 * time.Hour,
+}
+
 // Config Config file structure
 type Config struct {
-	Options Options           `yaml:"options"`
-	Servers map[string]Server `yaml:"servers"`
+	Options Options `yaml:"options"`
+	// Collectors options per collector name, applying to all servers
+	Collectors map[string]yaml.Node `yaml:"collectors"`
+	Servers    map[string]Server    `yaml:"servers"`
+	// ServerFiles globs of files to discover servers from, see discovery.FileDiscoverer
+	ServerFiles []string `yaml:"serverFiles"`
+	// HTTPDiscovery HTTP endpoints to discover servers from, see discovery.HTTPDiscoverer
+	HTTPDiscovery []HTTPDiscovery `yaml:"httpDiscovery"`
+	// SteamDiscovery discovers servers from the Steam master server or Web API, see discovery.SteamDiscoverer
+	SteamDiscovery []SteamDiscovery `yaml:"steamDiscovery"`
+	// DockerDiscovery discovers servers from labeled Docker containers, see discovery.DockerDiscoverer
+	DockerDiscovery *DockerDiscovery `yaml:"dockerDiscovery"`
+	// PlayerHistory records player sessions in the state store when set, see history.Recorder
+	PlayerHistory *PlayerHistory `yaml:"playerHistory"`
+	// API settings of the JSON API
+	API API `yaml:"api"`
+	// Notifications webhook notifications on server events, see notify.Notifier
+	Notifications Notifications `yaml:"notifications"`
+
+	// root the parsed config file, used to find the line numbers of problems
+	root *yaml.Node
 }
 
 // Options Options structure
@@ -31,15 +62,204 @@ type Options struct {
 	ConnectTimeout       time.Duration `yaml:"connectTimeout"`
 	CacheExpiration      time.Duration `yaml:"cacheExpiration"`
 	CacheCleanupInterval time.Duration `yaml:"cacheCleanupInterval"`
+	// RCONPassword default password for servers without a password
+	RCONPassword Secret `yaml:"rconPassword"`
+	// RCONPasswordFile default password file for servers without a password, takes precedence over RCONPassword
+	RCONPasswordFile string `yaml:"rconPasswordFile"`
+	// ServerFilesRefreshInterval interval in which server files are re-read in addition to watching them for changes
+	ServerFilesRefreshInterval time.Duration `yaml:"serverFilesRefreshInterval"`
+	// StateFile file of the state store persisting counters and history (e.g.,
+	// the map history) across restarts, relative to the config file. Nothing
+	// is persisted when empty.
+	StateFile string `yaml:"stateFile"`
+	// StateCompactionInterval interval in which the state store is compacted
+	StateCompactionInterval time.Duration `yaml:"stateCompactionInterval"`
 }
 
 // Server Server structure
 type Server struct {
-	Address      string    `yaml:"address"`
-	RCONPassword string    `yaml:"rconPassword"`
-	Mode         QueryMode `yaml:"mode"`
+	Address      string `yaml:"address"`
+	RCONPassword Secret `yaml:"rconPassword"`
+	// RCONPasswordFile file to read the password from, takes precedence over RCONPassword
+	RCONPasswordFile string    `yaml:"rconPasswordFile"`
+	Mode             QueryMode `yaml:"mode"`
+	// Labels static labels added to all metrics of the server
+	Labels map[string]string `yaml:"labels"`
+	// Collectors enabled for the server, if empty the collectors enabled by flag are used
+	Collectors []string `yaml:"collectors"`
+	// CollectorOptions options per collector name, overriding the global collector options
+	CollectorOptions map[string]yaml.Node `yaml:"collectorOptions"`
+}
+
+// HTTPDiscovery HTTP service discovery provider structure
+type HTTPDiscovery struct {
+	// Name of the provider, used in logs and must be unique
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Format of the response, `pterodactyl` or `generic`
+	Format HTTPDiscoveryFormat `yaml:"format"`
+	// APIKey sent as bearer token
+	APIKey Secret `yaml:"apiKey"`
+	// APIKeyFile file to read the API key from, takes precedence over APIKey
+	APIKeyFile      string        `yaml:"apiKeyFile"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+	Timeout         time.Duration `yaml:"timeout"`
+
+	// Mode used for the discovered servers, for the generic format only if the
+	// server doesn't set a mode
+	Mode QueryMode `yaml:"mode"`
+	// RCONPasswordFile used for the discovered servers, password files of
+	// generic format responses are ignored
+	RCONPasswordFile string `yaml:"rconPasswordFile"`
+	// Labels added to the discovered servers
+	Labels map[string]string `yaml:"labels"`
+}
+
+// HTTPDiscoveryFormat response format of a HTTP service discovery endpoint
+type HTTPDiscoveryFormat string
+
+const (
+	// PterodactylFormat Pterodactyl panel client or application API server list
+	PterodactylFormat HTTPDiscoveryFormat = "pterodactyl"
+	// GenericFormat JSON list of target groups, same as server files
+	GenericFormat HTTPDiscoveryFormat = "generic"
+)
+
+// DockerDiscovery Docker container service discovery structure
+type DockerDiscovery struct {
+	// Host Docker API address, `unix://` socket path or `tcp://` address
+	Host            string        `yaml:"host"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+	// Network of which the container IP is used, if a container is in multiple networks
+	Network string `yaml:"network"`
+	// Labels added to the discovered servers
+	Labels map[string]string `yaml:"labels"`
+}
+
+// API JSON API structure
+type API struct {
+	// Players which player details the server endpoints expose
+	Players PlayerPrivacy `yaml:"players"`
+	// CORSAllowedOrigins origins browsers may access the API from, `*` allows all
+	CORSAllowedOrigins []string `yaml:"corsAllowedOrigins"`
+}
+
+// PlayerPrivacy which player details are exposed by the API
+type PlayerPrivacy string
+
+const (
+	// PlayerPrivacyHidden only the player counts are exposed
+	PlayerPrivacyHidden PlayerPrivacy = "hidden"
+	// PlayerPrivacyNames the names of the players are exposed
+	PlayerPrivacyNames PlayerPrivacy = "names"
+	// PlayerPrivacyFull the names, SteamIDs, ping and packet loss of the
+	// players are exposed, IP addresses never are
+	PlayerPrivacyFull PlayerPrivacy = "full"
+)
+
+// Notifications webhook notification structure
+type Notifications struct {
+	// Webhooks targets notified on events, no events are detected without
+	Webhooks []Webhook `yaml:"webhooks"`
+	// PlayerThresholds player counts of which crossing is notified
+	PlayerThresholds []int `yaml:"playerThresholds"`
+	// ConnectionLostAfter consecutive failed scrapes after which a server is
+	// considered lost, 1 when unset
+	ConnectionLostAfter int `yaml:"connectionLostAfter"`
 }
 
+// Webhook webhook notification target structure
+type Webhook struct {
+	// Name of the webhook, used in logs and must be unique
+	Name string `yaml:"name"`
+	// URL the notifications are posted to, a secret as e.g. Discord webhook
+	// URLs contain the token
+	URL Secret `yaml:"url"`
+	// URLFile file to read the URL from, takes precedence over URL
+	URLFile string `yaml:"urlFile"`
+	// Format of the payload, `json` (default) or `discord`
+	Format WebhookFormat `yaml:"format"`
+	// Events notified, all when empty
+	Events []EventType `yaml:"events"`
+	// MaxPerMinute maximum notifications sent per minute, further
+	// notifications are delayed. 30 when unset.
+	MaxPerMinute int `yaml:"maxPerMinute"`
+	// Retries of failed notifications, 3 when unset
+	Retries int `yaml:"retries"`
+	// Timeout of each request, 10s when unset
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// WebhookFormat payload format of a webhook
+type WebhookFormat string
+
+const (
+	// JSONWebhookFormat the event as JSON
+	JSONWebhookFormat WebhookFormat = "json"
+	// DiscordWebhookFormat Discord webhook message
+	DiscordWebhookFormat WebhookFormat = "discord"
+)
+
+// EventType type of a notified server event
+type EventType string
+
+const (
+	// ConnectionLostEvent the server couldn't be scraped
+	ConnectionLostEvent EventType = "connectionLost"
+	// ConnectionRestoredEvent the server could be scraped again
+	ConnectionRestoredEvent EventType = "connectionRestored"
+	// MapChangedEvent the server changed the map
+	MapChangedEvent EventType = "mapChanged"
+	// PlayerThresholdEvent the player count crossed a threshold
+	PlayerThresholdEvent EventType = "playerThreshold"
+)
+
+// EventTypes all event types
+var EventTypes = []EventType{
+	ConnectionLostEvent,
+	ConnectionRestoredEvent,
+	MapChangedEvent,
+	PlayerThresholdEvent,
+}
+
+// PlayerHistory player session history structure
+type PlayerHistory struct {
+	// Retention time sessions are kept after they ended, 30 days when unset
+	Retention time.Duration `yaml:"retention"`
+}
+
+// SteamDiscovery Steam server list service discovery structure
+type SteamDiscovery struct {
+	// Name of the provider, used in logs and must be unique
+	Name string `yaml:"name"`
+	// Source of the server list, `master` or `webapi`
+	Source SteamSource `yaml:"source"`
+	// Filter server list filter, e.g., `\gameaddr\1.2.3.4`
+	Filter string `yaml:"filter"`
+	// MasterServer address of the master server for the `master` source
+	MasterServer string `yaml:"masterServer"`
+	// WebAPIURL base URL of the Steam Web API for the `webapi` source
+	WebAPIURL string `yaml:"webAPIURL"`
+	// APIKey Steam Web API key, required for the `webapi` source
+	APIKey Secret `yaml:"apiKey"`
+	// APIKeyFile file to read the API key from, takes precedence over APIKey
+	APIKeyFile      string        `yaml:"apiKeyFile"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+	Timeout         time.Duration `yaml:"timeout"`
+	// Labels added to the discovered servers
+	Labels map[string]string `yaml:"labels"`
+}
+
+// SteamSource where the Steam server list is requested from
+type SteamSource string
+
+const (
+	// MasterServerSource Steam master server query protocol over UDP
+	MasterServerSource SteamSource = "master"
+	// WebAPISource Steam Web API `IGameServersService/GetServerList`
+	WebAPISource SteamSource = "webapi"
+)
+
 // QueryMode which mode to talk to a server with
 type QueryMode string
 
@@ -47,4 +267,17 @@ const (
 	RCONMode        QueryMode = "RCON"
 	ServerQueryMode QueryMode = "ServerQuery"
 	A2SMode         QueryMode = "A2S"
+	// GoldSrcRCONMode GoldSrc (HLDS) servers, queried via UDP RCON and the GoldSrc info query
+	GoldSrcRCONMode QueryMode = "GoldSrcRCON"
+	// HybridMode queries info and player count via A2S and player details via RCON
+	HybridMode QueryMode = "hybrid"
 )
+
+// QueryModes all available query modes
+var QueryModes = []QueryMode{
+	RCONMode,
+	ServerQueryMode,
+	A2SMode,
+	GoldSrcRCONMode,
+	HybridMode,
+}
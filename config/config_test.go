@@ -0,0 +1,324 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestSecretIsMasked(t *testing.T) {
+	server := Server{
+		Address:      "127.0.0.1:27015",
+		RCONPassword: "hunter2",
+	}
+
+	for _, out := range []string{
+		fmt.Sprintf("%v", server),
+		fmt.Sprintf("%+v", server),
+		fmt.Sprintf("%#v", server),
+		fmt.Sprintf("%s", server.RCONPassword),
+	} {
+		assert.NotContains(t, out, "hunter2")
+	}
+
+	out, err := yaml.Marshal(server)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "hunter2")
+
+	out, err = json.Marshal(server)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "hunter2")
+
+	assert.Equal(t, "hunter2", string(server.RCONPassword))
+}
+
+var loadFileTests = []struct {
+	name      string
+	config    string
+	env       map[string]string
+	files     map[string]string
+	passwords map[string]Secret
+	errOkay   bool
+}{
+	{
+		name: "plain password",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: plain
+`,
+		passwords: map[string]Secret{"server1": "plain"},
+	},
+	{
+		name: "password file relative to config",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: ignored
+    rconPasswordFile: server1.password
+`,
+		files:     map[string]string{"server1.password": "from-file\n"},
+		passwords: map[string]Secret{"server1": "from-file"},
+	},
+	{
+		name: "missing password file",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPasswordFile: missing.password
+`,
+		errOkay: true,
+	},
+	{
+		name: "environment variable",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: ${SRCDS_TEST_PASSWORD}
+`,
+		env:       map[string]string{"SRCDS_TEST_PASSWORD": "from-env"},
+		passwords: map[string]Secret{"server1": "from-env"},
+	},
+	{
+		name: "unset environment variable",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: ${SRCDS_TEST_UNSET_PASSWORD}
+`,
+		errOkay: true,
+	},
+	{
+		name: "environment variable with YAML syntax",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: ${SRCDS_TEST_PASSWORD}
+`,
+		env:       map[string]string{"SRCDS_TEST_PASSWORD": "pass #word: x\nmode: A2S"},
+		passwords: map[string]Secret{"server1": "pass #word: x\nmode: A2S"},
+	},
+	{
+		name: "unset environment variable in comment",
+		config: `servers:
+  # rconPassword: ${SRCDS_TEST_UNSET_PASSWORD}
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: plain # ${SRCDS_TEST_UNSET_PASSWORD}
+`,
+		passwords: map[string]Secret{"server1": "plain"},
+	},
+	{
+		name: "default password from options",
+		config: `options:
+  rconPasswordFile: default.password
+servers:
+  server1:
+    address: 127.0.0.1:27015
+  server2:
+    address: 127.0.0.1:27016
+    rconPassword: own
+`,
+		files:     map[string]string{"default.password": "default"},
+		passwords: map[string]Secret{"server1": "default", "server2": "own"},
+	},
+}
+
+func TestLoadFile(t *testing.T) {
+	for _, tt := range loadFileTests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			file := filepath.Join(dir, "srcds.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tt.config), 0o600))
+
+			c, err := LoadFile(file)
+			if tt.errOkay {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for name, password := range tt.passwords {
+				assert.Equal(t, password, c.Servers[name].RCONPassword, name)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultsAndStrict(t *testing.T) {
+	c, err := Load([]byte(`options:
+  connectTimeout: 2s
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, c.Options.ConnectTimeout)
+	assert.Equal(t, DefaultOptions.CacheExpiration, c.Options.CacheExpiration)
+	assert.Equal(t, DefaultOptions.CacheCleanupInterval, c.Options.CacheCleanupInterval)
+	assert.Equal(t, RCONMode, c.Servers["server1"].Mode)
+
+	_, err = Load([]byte(`servers:
+  server1:
+    adress: 127.0.0.1:27015
+`))
+	assert.ErrorContains(t, err, "line 3: field adress not found")
+}
+
+var validateTests = []struct {
+	name     string
+	config   string
+	expected []ValidationError
+}{
+	{
+		name: "valid",
+		config: `servers:
+  server1:
+    address: 127.0.0.1:27015
+    rconPassword: test
+  server2:
+    address: 127.0.0.1:27016
+    mode: A2S
+`,
+	},
+	{
+		name: "all problems",
+		config: `options:
+  cacheExpiration: 0s
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: RCOM
+  server2:
+    address: 127.0.0.1
+  server3:
+    address: 127.0.0.1:27015
+    mode: A2S
+    labels:
+      region: eu
+      map: de_dust2
+      __meta: "true"
+      1game: css
+      team: red
+`,
+		expected: []ValidationError{
+			{Line: 2, Message: "options: cacheExpiration must be greater than 0, got 0s"},
+			{Line: 6, Message: `server "server1": unknown mode "RCOM", must be one of RCON, ServerQuery, A2S, GoldSrcRCON, hybrid`},
+			{Line: 7, Message: `server "server2": mode RCON requires rconPassword or rconPasswordFile (or a default in options)`},
+			{Line: 8, Message: `server "server2": invalid address "127.0.0.1", must be host:port: address 127.0.0.1: missing port in address`},
+			{Line: 10, Message: `server "server3": address 127.0.0.1:27015 is already used by server "server1"`},
+			{Line: 14, Message: `server "server3": label name "map" is reserved`},
+			{Line: 15, Message: `server "server3": label name "__meta" is reserved, names starting with __ are for internal use`},
+			{Line: 16, Message: `server "server3": invalid label name "1game"`},
+			{Line: 17, Message: `server "server3": label name "team" is reserved`},
+		},
+	},
+	{
+		name: "player history without state file",
+		config: `playerHistory:
+  retention: -1h
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: A2S
+`,
+		expected: []ValidationError{
+			{Line: 1, Message: "playerHistory: requires options.stateFile to be set"},
+			{Line: 1, Message: "playerHistory: retention can't be negative"},
+		},
+	},
+	{
+		name: "api",
+		config: `api:
+  players: everything
+  corsAllowedOrigins:
+    - "*"
+    - https://example.com
+    - example.com
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: A2S
+`,
+		expected: []ValidationError{
+			{Line: 2, Message: `api: unknown players "everything", must be one of hidden, names, full`},
+			{Line: 3, Message: "api: invalid CORS origin \"example.com\", must be `*` or scheme://host[:port]"},
+		},
+	},
+	{
+		name: "notifications",
+		config: `notifications:
+  webhooks:
+    - name: discord
+      url: https://discord.com/api/webhooks/1/token
+      format: discord
+    - name: discord
+      url: discord.com/api/webhooks/1/token
+      format: slack
+      events:
+        - serverExploded
+      retries: -1
+  playerThresholds: [0, 10]
+servers:
+  server1:
+    address: 127.0.0.1:27015
+    mode: A2S
+`,
+		expected: []ValidationError{
+			{Line: 2, Message: `notifications: webhooks[1]: name "discord" is used more than once`},
+			{Line: 2, Message: "notifications: webhooks[1]: url or urlFile is required and must be a http(s) URL"},
+			{Line: 2, Message: `notifications: webhooks[1]: unknown format "slack", must be one of json, discord`},
+			{Line: 2, Message: `notifications: webhooks[1]: unknown event "serverExploded"`},
+			{Line: 2, Message: "notifications: webhooks[1]: maxPerMinute, retries and timeout can't be negative"},
+			{Line: 12, Message: "notifications: player thresholds must be greater than 0, got 0"},
+		},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Load([]byte(tt.config))
+			require.NoError(t, err)
+
+			err = c.Validate()
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verrs ValidationErrors
+			require.ErrorAs(t, err, &verrs)
+			assert.Equal(t, ValidationErrors(tt.expected), verrs)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var envVarRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadFile reads, parses the config file and resolves the RCON passwords.
+// Password files are read relative to the config file's directory.
+func LoadFile(file string) (*Config, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Load(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.resolvePasswords(filepath.Dir(file)); err != nil {
+		return nil, err
+	}
+	if c.Options.StateFile != "" && !filepath.IsAbs(c.Options.StateFile) {
+		c.Options.StateFile = filepath.Join(filepath.Dir(file), c.Options.StateFile)
+	}
+
+	return c, nil
+}
+
+// Load parses the config, `${ENV_VAR}` references in string values are
+// replaced by the value of the environment variable. Unknown keys are an
+// error and unset options are set to their defaults.
+func Load(content []byte) (*Config, error) {
+	// Decoded once before expanding to check for unknown keys and invalid
+	// values, with the line numbers of the config file
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(newConfig()); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	// Kept to be able to point to the line of a problem during validation
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return nil, err
+	}
+	if err := expandEnv(root); err != nil {
+		return nil, err
+	}
+
+	c := newConfig()
+	if len(root.Content) > 0 {
+		if err := root.Decode(c); err != nil {
+			return nil, err
+		}
+	}
+	c.root = root
+
+	for name, server := range c.Servers {
+		if server.Mode == "" {
+			server.Mode = RCONMode
+			c.Servers[name] = server
+		}
+	}
+
+	return c, nil
+}
+
+func newConfig() *Config {
+	return &Config{
+		Options: DefaultOptions,
+		API: API{
+			Players: PlayerPrivacyNames,
+		},
+	}
+}
+
+// expandEnv replaces `${ENV_VAR}` references in the string values of the
+// parsed config, unset variables are an error. Keys and comments are left
+// untouched and the values are never parsed as YAML again.
+func expandEnv(root *yaml.Node) error {
+	missing := map[string]struct{}{}
+	expandEnvNode(root, missing)
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("environment variables referenced in config are not set: %s", strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+func expandEnvNode(node *yaml.Node, missing map[string]struct{}) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, n := range node.Content {
+			expandEnvNode(n, missing)
+		}
+	case yaml.MappingNode:
+		// Only the values, keys are at the even indexes
+		for i := 1; i < len(node.Content); i += 2 {
+			expandEnvNode(node.Content[i], missing)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" {
+			return
+		}
+		node.Value = envVarRegex.ReplaceAllStringFunc(node.Value, func(match string) string {
+			name := envVarRegex.FindStringSubmatch(match)[1]
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				missing[name] = struct{}{}
+				return match
+			}
+			return value
+		})
+	}
+}
+
+// resolvePasswords sets the RCON password of each server from its password
+// file or the default password from the options
+func (c *Config) resolvePasswords(dir string) error {
+	defaultPassword := c.Options.RCONPassword
+	if c.Options.RCONPasswordFile != "" {
+		password, err := ReadPasswordFile(dir, c.Options.RCONPasswordFile)
+		if err != nil {
+			return fmt.Errorf("options: %w", err)
+		}
+		defaultPassword = password
+	}
+	// Resolved default, used for servers added by service discovery
+	c.Options.RCONPassword = defaultPassword
+
+	for i, h := range c.HTTPDiscovery {
+		// The password file is read on every refresh, make it independent of the working directory
+		if h.RCONPasswordFile != "" && !filepath.IsAbs(h.RCONPasswordFile) {
+			c.HTTPDiscovery[i].RCONPasswordFile = filepath.Join(dir, h.RCONPasswordFile)
+		}
+		if h.APIKeyFile == "" {
+			continue
+		}
+		key, err := ReadPasswordFile(dir, h.APIKeyFile)
+		if err != nil {
+			return fmt.Errorf("httpDiscovery %q: %w", h.Name, err)
+		}
+		c.HTTPDiscovery[i].APIKey = key
+	}
+
+	for i, st := range c.SteamDiscovery {
+		if st.APIKeyFile == "" {
+			continue
+		}
+		key, err := ReadPasswordFile(dir, st.APIKeyFile)
+		if err != nil {
+			return fmt.Errorf("steamDiscovery %q: %w", st.Name, err)
+		}
+		c.SteamDiscovery[i].APIKey = key
+	}
+
+	for i, w := range c.Notifications.Webhooks {
+		if w.URLFile == "" {
+			continue
+		}
+		u, err := ReadPasswordFile(dir, w.URLFile)
+		if err != nil {
+			return fmt.Errorf("notifications webhook %q: %w", w.Name, err)
+		}
+		c.Notifications.Webhooks[i].URL = u
+	}
+
+	for name, server := range c.Servers {
+		switch {
+		case server.RCONPasswordFile != "":
+			password, err := ReadPasswordFile(dir, server.RCONPasswordFile)
+			if err != nil {
+				return fmt.Errorf("server %s: %w", name, err)
+			}
+			server.RCONPassword = password
+		case server.RCONPassword == "":
+			server.RCONPassword = defaultPassword
+		}
+		c.Servers[name] = server
+	}
+
+	return nil
+}
+
+// ReadPasswordFile reads a password from the file, relative paths are
+// resolved against dir. Trailing newlines are removed.
+func ReadPasswordFile(dir string, file string) (Secret, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return Secret(strings.TrimRight(string(content), "\r\n")), nil
+}
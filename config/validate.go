@@ -0,0 +1,425 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var labelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ReservedLabels label names used by the exporter's metrics which can't be used as static server labels
+var ReservedLabels = []string{
+	"server",
+	"map",
+	"steamid",
+	"collector",
+	"instance",
+	"job",
+	// sourcemod and metamod collectors
+	"name",
+	"version",
+	"status",
+	// match collector
+	"team",
+	"phase",
+	"cvar",
+}
+
+// TargetLabels label names added to all metrics of a server by the exporter
+// or Prometheus, which can't be used as labels of custom metrics
+var TargetLabels = []string{
+	"server",
+	"instance",
+	"job",
+}
+
+// ValidationError a problem found in the config
+type ValidationError struct {
+	// Line in the config file, 0 if unknown
+	Line    int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// ValidationErrors all problems found in the config
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d problem(s) found in config:\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
+
+// Validate checks the config for problems, all found problems are returned
+// as ValidationErrors sorted by line.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	add := func(path []string, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{
+			Line:    c.Line(path...),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, opt := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"connectTimeout", c.Options.ConnectTimeout},
+		{"cacheExpiration", c.Options.CacheExpiration},
+		{"cacheCleanupInterval", c.Options.CacheCleanupInterval},
+		{"serverFilesRefreshInterval", c.Options.ServerFilesRefreshInterval},
+		{"stateCompactionInterval", c.Options.StateCompactionInterval},
+	} {
+		if opt.value <= 0 {
+			add([]string{"options", opt.key}, "options: %s must be greater than 0, got %s", opt.key, opt.value)
+		}
+	}
+
+	for i, pattern := range c.ServerFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			add([]string{"serverFiles"}, "serverFiles: invalid glob %q at index %d: %s", pattern, i, err)
+		}
+	}
+
+	httpNames := map[string]struct{}{}
+	for i, h := range c.HTTPDiscovery {
+		path := []string{"httpDiscovery"}
+		if h.Name == "" {
+			add(path, "httpDiscovery[%d]: name is required", i)
+		} else if _, ok := httpNames[h.Name]; ok {
+			add(path, "httpDiscovery[%d]: name %q is used more than once", i, h.Name)
+		}
+		httpNames[h.Name] = struct{}{}
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			add(path, "httpDiscovery[%d]: invalid url %q, must be a http(s) URL", i, h.URL)
+		}
+		if h.Format != PterodactylFormat && h.Format != GenericFormat {
+			add(path, "httpDiscovery[%d]: unknown format %q, must be one of %s, %s", i, h.Format, PterodactylFormat, GenericFormat)
+		}
+		if h.Mode != "" && !h.Mode.IsValid() {
+			add(path, "httpDiscovery[%d]: unknown mode %q, must be one of %s", i, h.Mode, joinModes(QueryModes))
+		}
+		if h.RefreshInterval < 0 || h.Timeout < 0 {
+			add(path, "httpDiscovery[%d]: refreshInterval and timeout can't be negative", i)
+		}
+		for label := range h.Labels {
+			if err := ValidateLabelName(label); err != nil {
+				add(path, "httpDiscovery[%d]: %s", i, err)
+			}
+		}
+	}
+
+	steamNames := map[string]struct{}{}
+	for i, st := range c.SteamDiscovery {
+		path := []string{"steamDiscovery"}
+		if st.Name == "" {
+			add(path, "steamDiscovery[%d]: name is required", i)
+		} else if _, ok := steamNames[st.Name]; ok {
+			add(path, "steamDiscovery[%d]: name %q is used more than once", i, st.Name)
+		}
+		steamNames[st.Name] = struct{}{}
+		if st.Filter == "" {
+			add(path, "steamDiscovery[%d]: filter is required", i)
+		}
+		switch st.Source {
+		case "", MasterServerSource:
+			if st.MasterServer != "" {
+				if _, _, err := net.SplitHostPort(st.MasterServer); err != nil {
+					add(path, "steamDiscovery[%d]: invalid masterServer %q: %s", i, st.MasterServer, err)
+				}
+			}
+		case WebAPISource:
+			if st.APIKey == "" {
+				add(path, "steamDiscovery[%d]: apiKey or apiKeyFile is required for the %s source", i, WebAPISource)
+			}
+			if st.WebAPIURL != "" {
+				if u, err := url.Parse(st.WebAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					add(path, "steamDiscovery[%d]: invalid webAPIURL %q, must be a http(s) URL", i, st.WebAPIURL)
+				}
+			}
+		default:
+			add(path, "steamDiscovery[%d]: unknown source %q, must be one of %s, %s", i, st.Source, MasterServerSource, WebAPISource)
+		}
+		if st.RefreshInterval < 0 || st.Timeout < 0 {
+			add(path, "steamDiscovery[%d]: refreshInterval and timeout can't be negative", i)
+		}
+		for label := range st.Labels {
+			if err := ValidateLabelName(label); err != nil {
+				add(path, "steamDiscovery[%d]: %s", i, err)
+			}
+		}
+	}
+
+	if d := c.DockerDiscovery; d != nil {
+		path := []string{"dockerDiscovery"}
+		if d.Host != "" {
+			if u, err := url.Parse(d.Host); err != nil || (u.Scheme != "unix" && u.Scheme != "tcp") {
+				add(path, "dockerDiscovery: invalid host %q, must be a unix:// or tcp:// address", d.Host)
+			}
+		}
+		if d.RefreshInterval < 0 {
+			add(path, "dockerDiscovery: refreshInterval can't be negative")
+		}
+		for label := range d.Labels {
+			if err := ValidateLabelName(label); err != nil {
+				add(path, "dockerDiscovery: %s", err)
+			}
+		}
+	}
+
+	if h := c.PlayerHistory; h != nil {
+		path := []string{"playerHistory"}
+		if c.Options.StateFile == "" {
+			add(path, "playerHistory: requires options.stateFile to be set")
+		}
+		if h.Retention < 0 {
+			add(path, "playerHistory: retention can't be negative")
+		}
+	}
+
+	switch c.API.Players {
+	case PlayerPrivacyHidden, PlayerPrivacyNames, PlayerPrivacyFull:
+	default:
+		add([]string{"api", "players"}, "api: unknown players %q, must be one of hidden, names, full", c.API.Players)
+	}
+	for _, origin := range c.API.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			add([]string{"api", "corsAllowedOrigins"}, "api: invalid CORS origin %q, must be `*` or scheme://host[:port]", origin)
+		}
+	}
+
+	webhookNames := map[string]struct{}{}
+	for i, w := range c.Notifications.Webhooks {
+		path := []string{"notifications", "webhooks"}
+		if w.Name == "" {
+			add(path, "notifications: webhooks[%d]: name is required", i)
+		} else if _, ok := webhookNames[w.Name]; ok {
+			add(path, "notifications: webhooks[%d]: name %q is used more than once", i, w.Name)
+		}
+		webhookNames[w.Name] = struct{}{}
+		// The URL isn't printed, as it may contain a token
+		if u, err := url.Parse(string(w.URL)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(path, "notifications: webhooks[%d]: url or urlFile is required and must be a http(s) URL", i)
+		}
+		if w.Format != "" && w.Format != JSONWebhookFormat && w.Format != DiscordWebhookFormat {
+			add(path, "notifications: webhooks[%d]: unknown format %q, must be one of %s, %s", i, w.Format, JSONWebhookFormat, DiscordWebhookFormat)
+		}
+		for _, event := range w.Events {
+			if !slices.Contains(EventTypes, event) {
+				add(path, "notifications: webhooks[%d]: unknown event %q", i, event)
+			}
+		}
+		if w.MaxPerMinute < 0 || w.Retries < 0 || w.Timeout < 0 {
+			add(path, "notifications: webhooks[%d]: maxPerMinute, retries and timeout can't be negative", i)
+		}
+	}
+	for _, threshold := range c.Notifications.PlayerThresholds {
+		if threshold <= 0 {
+			add([]string{"notifications", "playerThresholds"}, "notifications: player thresholds must be greater than 0, got %d", threshold)
+		}
+	}
+	if c.Notifications.ConnectionLostAfter < 0 {
+		add([]string{"notifications", "connectionLostAfter"}, "notifications: connectionLostAfter can't be negative")
+	}
+
+	addresses := map[string]string{}
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		server := c.Servers[name]
+		path := []string{"servers", name}
+
+		if server.Address != "" {
+			if other, ok := addresses[server.Address]; ok {
+				add(append(path, "address"), "server %q: address %s is already used by server %q", name, server.Address, other)
+			} else {
+				addresses[server.Address] = name
+			}
+		}
+
+		for _, p := range server.problems() {
+			add(append(path, p.path...), "server %q: %s", name, p.message)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
+
+type problem struct {
+	// path of the key below the server
+	path    []string
+	message string
+}
+
+// Validate checks the server for problems, e.g., servers added by service discovery
+func (s Server) Validate() error {
+	problems := s.problems()
+	if len(problems) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(problems))
+	for _, p := range problems {
+		msgs = append(msgs, p.message)
+	}
+	return errors.New(strings.Join(msgs, ", "))
+}
+
+func (s Server) problems() []problem {
+	var problems []problem
+	add := func(path []string, format string, args ...interface{}) {
+		problems = append(problems, problem{
+			path:    path,
+			message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s.Address == "" {
+		add(nil, "address is required")
+	} else if _, _, err := net.SplitHostPort(s.Address); err != nil {
+		add([]string{"address"}, "invalid address %q, must be host:port: %s", s.Address, err)
+	}
+
+	labelNames := make([]string, 0, len(s.Labels))
+	for label := range s.Labels {
+		labelNames = append(labelNames, label)
+	}
+	sort.Strings(labelNames)
+	for _, label := range labelNames {
+		if err := ValidateLabelName(label); err != nil {
+			add([]string{"labels", label}, "%s", err)
+		}
+	}
+
+	if !s.Mode.IsValid() {
+		add([]string{"mode"}, "unknown mode %q, must be one of %s", s.Mode, joinModes(QueryModes))
+	} else if (s.Mode == RCONMode || s.Mode == GoldSrcRCONMode || s.Mode == HybridMode) && s.RCONPassword == "" {
+		add(nil, "mode %s requires rconPassword or rconPasswordFile (or a default in options)", s.Mode)
+	}
+
+	return problems
+}
+
+// ValidateLabelName checks that the name is a valid Prometheus label name which isn't reserved
+func ValidateLabelName(name string) error {
+	return validateLabelName(name, ReservedLabels)
+}
+
+// ValidateMetricLabelName checks that the name is a valid Prometheus label
+// name which isn't one of the TargetLabels
+func ValidateMetricLabelName(name string) error {
+	return validateLabelName(name, TargetLabels)
+}
+
+func validateLabelName(name string, reserved []string) error {
+	if !labelNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	if strings.HasPrefix(name, "__") {
+		return fmt.Errorf("label name %q is reserved, names starting with __ are for internal use", name)
+	}
+	if slices.Contains(reserved, name) {
+		return fmt.Errorf("label name %q is reserved", name)
+	}
+	return nil
+}
+
+// RequiresA2S whether the query mode sends A2S queries, which have to be
+// enabled with the --a2s flag
+func (m QueryMode) RequiresA2S() bool {
+	return m == A2SMode || m == HybridMode
+}
+
+// IsValid whether the query mode is known
+func (m QueryMode) IsValid() bool {
+	for _, mode := range QueryModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func joinModes(modes []QueryMode) string {
+	out := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		out = append(out, string(mode))
+	}
+	return strings.Join(out, ", ")
+}
+
+// Line returns the line of the given key path in the config file, or of the
+// deepest existing parent key. 0 when the config wasn't loaded from a file.
+func (c *Config) Line(path ...string) int {
+	if c.root == nil || len(c.root.Content) == 0 {
+		return 0
+	}
+
+	node := c.root.Content[0]
+	line := node.Line
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
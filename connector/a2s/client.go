@@ -0,0 +1,391 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package a2s implements the Valve A2S server query protocol for Source
+// servers, including challenges, split and bzip2 compressed responses, and
+// the older GoldSrc variant of it.
+package a2s
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout timeout for each packet read when none is given
+	DefaultTimeout = 3 * time.Second
+	// MaxChallengeRetries number of times a request is resent with a new
+	// challenge before giving up
+	MaxChallengeRetries = 3
+
+	// maxPacketSize the largest UDP packet a server sends
+	maxPacketSize = 1400
+	// maxDecompressedSize upper limit for the size of a bzip2 compressed
+	// response, so a bogus header can't make us allocate arbitrary memory
+	maxDecompressedSize = 1 << 20
+
+	singlePacket int32 = -1
+	splitPacket  int32 = -2
+
+	infoRequest    = 'T'
+	infoResponse   = 'I'
+	playerRequest  = 'U'
+	playerResponse = 'D'
+	rulesRequest   = 'V'
+	rulesResponse  = 'E'
+	challengeReply = 'A'
+
+	goldSrcInfoResponse = 'm'
+)
+
+var (
+	// ErrChallenge the server kept replying with a challenge
+	ErrChallenge = errors.New("a2s: server kept sending new challenges")
+	// ErrBadChecksum the CRC32 of a decompressed response didn't match
+	ErrBadChecksum = errors.New("a2s: checksum mismatch of decompressed response")
+
+	infoPayload = []byte("Source Engine Query\x00")
+)
+
+// Client A2S query client for one server. It is safe for concurrent use,
+// queries are sent one at a time.
+type Client struct {
+	conn    net.Conn
+	timeout time.Duration
+
+	mu sync.Mutex
+	// goldSrc set for GoldSrc servers, which use a shorter split packet header
+	goldSrc bool
+	// noSplitSize set for games whose split packets lack the split size field
+	noSplitSize bool
+	buf         []byte
+}
+
+// NewClient creates a new client for the server at addr, timeout applies to
+// each packet read, DefaultTimeout is used when it is zero
+func NewClient(addr string, timeout time.Duration) (*Client, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:    conn,
+		timeout: timeout,
+		buf:     make([]byte, maxPacketSize),
+	}, nil
+}
+
+// NewGoldSrcClient creates a new client for the GoldSrc (HLDS) server at
+// addr, see NewClient
+func NewGoldSrcClient(addr string, timeout time.Duration) (*Client, error) {
+	c, err := NewClient(addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.goldSrc = true
+	return c, nil
+}
+
+// Close closes the UDP socket of the client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// QueryInfo sends an A2S_INFO query. GoldSrc servers may answer in the
+// obsolete GoldSrc format, which lacks the app ID and version.
+func (c *Client) QueryInfo() (*Info, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	typ, data, err := c.query(infoRequest, infoPayload, true, infoResponse, goldSrcInfoResponse)
+	if err != nil {
+		return nil, err
+	}
+	if typ == goldSrcInfoResponse {
+		return parseGoldSrcInfo(data)
+	}
+	info, err := parseInfo(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// These games send split packets without the split size field
+	switch info.AppID {
+	case 215, 17550, 17700:
+		c.noSplitSize = true
+	case 240:
+		c.noSplitSize = info.Protocol == 7
+	}
+
+	return info, nil
+}
+
+// QueryPlayers sends an A2S_PLAYER query
+func (c *Client) QueryPlayers() ([]Player, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, data, err := c.query(playerRequest, nil, false, playerResponse)
+	if err != nil {
+		return nil, err
+	}
+	return parsePlayers(data)
+}
+
+// QueryRules sends an A2S_RULES query
+func (c *Client) QueryRules() (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, data, err := c.query(rulesRequest, nil, false, rulesResponse)
+	if err != nil {
+		return nil, err
+	}
+	return parseRules(data)
+}
+
+// Exchange sends a connectionless packet with the payload, e.g., a GoldSrc
+// RCON command, and returns the response after the packet header
+func (c *Client) Exchange(payload []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	req := append([]byte{0xFF, 0xFF, 0xFF, 0xFF}, payload...)
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+	return c.receive(c.timeout)
+}
+
+// Receive waits up to timeout for another response, e.g., the remainder of a
+// long GoldSrc RCON output, and returns it after the packet header
+func (c *Client) Receive(timeout time.Duration) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.receive(timeout)
+}
+
+// query sends a request and returns the type and body of the response.
+// Challenge replies are answered by resending the request with the challenge,
+// either appended to the payload (A2S_INFO) or in place of it.
+func (c *Client) query(typ byte, payload []byte, appendChallenge bool, expected ...byte) (byte, []byte, error) {
+	challenge := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	withChallenge := false
+
+	for range MaxChallengeRetries + 1 {
+		req := []byte{0xFF, 0xFF, 0xFF, 0xFF, typ}
+		req = append(req, payload...)
+		if !appendChallenge || withChallenge {
+			req = append(req, challenge...)
+		}
+		if _, err := c.conn.Write(req); err != nil {
+			return 0, nil, err
+		}
+
+		resp, err := c.receive(c.timeout)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(resp) == 0 {
+			return 0, nil, fmt.Errorf("a2s: empty response")
+		}
+
+		switch {
+		case bytes.IndexByte(expected, resp[0]) >= 0:
+			return resp[0], resp[1:], nil
+		case resp[0] == challengeReply:
+			if len(resp) < 5 {
+				return 0, nil, fmt.Errorf("a2s: short challenge response")
+			}
+			challenge = append(challenge[:0], resp[1:5]...)
+			withChallenge = true
+		default:
+			return 0, nil, fmt.Errorf("a2s: unexpected response type 0x%02X, expected 0x%02X", resp[0], expected[0])
+		}
+	}
+
+	return 0, nil, ErrChallenge
+}
+
+// receive reads a response, reassembling split packets, waiting up to
+// timeout for the first packet
+func (c *Client) receive(timeout time.Duration) ([]byte, error) {
+	packet, err := c.read(timeout)
+	if err != nil {
+		return nil, err
+	}
+	if len(packet) < 4 {
+		return nil, fmt.Errorf("a2s: short packet of %d bytes", len(packet))
+	}
+
+	switch int32(binary.LittleEndian.Uint32(packet)) {
+	case singlePacket:
+		return bytes.Clone(packet[4:]), nil
+	case splitPacket:
+		return c.receiveSplit(packet)
+	default:
+		return nil, fmt.Errorf("a2s: unknown packet header 0x%08X", binary.LittleEndian.Uint32(packet))
+	}
+}
+
+// read reads one packet, the returned slice is only valid until the next read
+func (c *Client) read(timeout time.Duration) ([]byte, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	n, err := c.conn.Read(c.buf)
+	if err != nil {
+		return nil, err
+	}
+	return c.buf[:n], nil
+}
+
+// splitHeader header of a packet of a split response
+type splitHeader struct {
+	ID         uint32
+	Total      byte
+	Number     byte
+	Compressed bool
+}
+
+// parseSplitHeader parses the header of a split packet and returns the
+// payload following it
+func (c *Client) parseSplitHeader(packet []byte) (splitHeader, []byte, error) {
+	if c.goldSrc {
+		// The header (-2) is followed by the ID and the packet number (upper
+		// 4 bits) and total (lower 4 bits), GoldSrc doesn't compress responses
+		if len(packet) < 9 {
+			return splitHeader{}, nil, fmt.Errorf("a2s: short split packet of %d bytes", len(packet))
+		}
+		h := splitHeader{
+			ID:     binary.LittleEndian.Uint32(packet[4:8]),
+			Total:  packet[8] & 0x0F,
+			Number: packet[8] >> 4,
+		}
+		if h.Total == 0 || h.Number >= h.Total {
+			return splitHeader{}, nil, fmt.Errorf("a2s: invalid split packet %d of %d", h.Number, h.Total)
+		}
+		return h, packet[9:], nil
+	}
+
+	size := 12
+	if c.noSplitSize {
+		size = 10
+	}
+	// The header (-2) is followed by the ID, total, number and split size
+	if len(packet) < size {
+		return splitHeader{}, nil, fmt.Errorf("a2s: short split packet of %d bytes", len(packet))
+	}
+
+	id := binary.LittleEndian.Uint32(packet[4:8])
+	h := splitHeader{
+		ID:         id &^ 0x80000000,
+		Total:      packet[8],
+		Number:     packet[9],
+		Compressed: id&0x80000000 != 0,
+	}
+	if h.Total == 0 || h.Number >= h.Total {
+		return splitHeader{}, nil, fmt.Errorf("a2s: invalid split packet %d of %d", h.Number, h.Total)
+	}
+	return h, packet[size:], nil
+}
+
+// receiveSplit reads the remaining packets of a split response. Packets may
+// arrive out of order, packets of other (stale) responses are skipped.
+func (c *Client) receiveSplit(first []byte) ([]byte, error) {
+	h, payload, err := c.parseSplitHeader(first)
+	if err != nil {
+		return nil, err
+	}
+
+	id, compressed := h.ID, h.Compressed
+	parts := make([][]byte, h.Total)
+	parts[h.Number] = bytes.Clone(payload)
+	received := 1
+
+	for received < len(parts) {
+		packet, err := c.read(c.timeout)
+		if err != nil {
+			return nil, fmt.Errorf("a2s: received %d of %d split packets: %w", received, len(parts), err)
+		}
+		if len(packet) < 4 || int32(binary.LittleEndian.Uint32(packet)) != splitPacket {
+			continue
+		}
+		h, payload, err := c.parseSplitHeader(packet)
+		if err != nil {
+			return nil, err
+		}
+		if h.ID != id {
+			continue
+		}
+		if int(h.Total) != len(parts) {
+			return nil, fmt.Errorf("a2s: split packet total changed from %d to %d", len(parts), h.Total)
+		}
+		if parts[h.Number] != nil {
+			continue
+		}
+		parts[h.Number] = bytes.Clone(payload)
+		received++
+	}
+
+	data := bytes.Join(parts, nil)
+	if compressed {
+		if data, err = decompress(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// The joined payload starts with the single packet header again
+	if len(data) < 4 || int32(binary.LittleEndian.Uint32(data)) != singlePacket {
+		return nil, fmt.Errorf("a2s: invalid header in reassembled response")
+	}
+	return data[4:], nil
+}
+
+// decompress decompresses a bzip2 compressed response, which starts with the
+// decompressed size and its CRC32 checksum
+func decompress(data []byte) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("a2s: short compressed response")
+	}
+	size := binary.LittleEndian.Uint32(data[0:4])
+	checksum := binary.LittleEndian.Uint32(data[4:8])
+	if size > maxDecompressedSize {
+		return nil, fmt.Errorf("a2s: decompressed size %d exceeds limit of %d", size, maxDecompressedSize)
+	}
+
+	out := make([]byte, size)
+	if _, err := io.ReadFull(bzip2.NewReader(bytes.NewReader(data[8:])), out); err != nil {
+		return nil, fmt.Errorf("a2s: failed to decompress response: %w", err)
+	}
+	if crc32.ChecksumIEEE(out) != checksum {
+		return nil, ErrBadChecksum
+	}
+	return out, nil
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package a2s
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// challenge the challenge sent in testdata/challenge.bin
+	challenge = []byte{0x21, 0x9E, 0x3C, 0x5A}
+	// rotatedChallenge the challenge sent in testdata/challenge_rotated.bin
+	rotatedChallenge = []byte{0x44, 0x33, 0x22, 0x11}
+
+	infoQuery   = []byte("\xFF\xFF\xFF\xFFTSource Engine Query\x00")
+	playerQuery = []byte("\xFF\xFF\xFF\xFFU")
+	rulesQuery  = []byte("\xFF\xFF\xFF\xFFV")
+)
+
+// fixture returns the content of a packet from testdata. The packets are
+// synthetic, built by hand to match the A2S wire format, not captured from
+// real servers.
+func fixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name+".bin"))
+	require.NoError(t, err)
+	return data
+}
+
+// replay starts a UDP responder answering each request with the packets
+// returned by handler
+func replay(t *testing.T, handler func(req []byte) [][]byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			for _, packet := range handler(bytes.Clone(buf[:n])) {
+				conn.WriteTo(packet, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// withChallenge answers requests without the expected challenge with the
+// challenge fixture and all others via handler
+func withChallenge(t *testing.T, query []byte, handler func() [][]byte) func(req []byte) [][]byte {
+	return func(req []byte) [][]byte {
+		if !bytes.Equal(req, append(bytes.Clone(query), challenge...)) {
+			return [][]byte{fixture(t, "challenge")}
+		}
+		return handler()
+	}
+}
+
+func newTestClient(t *testing.T, addr string) *Client {
+	c, err := NewClient(addr, 500*time.Millisecond)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestQueryInfo(t *testing.T) {
+	addr := replay(t, withChallenge(t, infoQuery, func() [][]byte {
+		return [][]byte{fixture(t, "info")}
+	}))
+	c := newTestClient(t, addr)
+
+	info, err := c.QueryInfo()
+	require.NoError(t, err)
+	assert.Equal(t, &Info{
+		Protocol:    17,
+		Name:        "Synthetic Server",
+		Map:         "de_dust2",
+		Folder:      "cstrike",
+		Game:        "Counter-Strike: Source",
+		AppID:       240,
+		Players:     5,
+		MaxPlayers:  24,
+		Bots:        1,
+		ServerType:  'd',
+		Environment: 'l',
+		VAC:         true,
+		Version:     "9540945",
+		Port:        27015,
+		SteamID:     90071992547409920,
+		Keywords:    "alltalk,increased_maxplayers",
+		GameID:      240,
+	}, info)
+}
+
+func TestQueryInfoRotatedChallenge(t *testing.T) {
+	// The first challenge is replaced before the request with it arrives
+	addr := replay(t, func(req []byte) [][]byte {
+		switch {
+		case bytes.Equal(req, infoQuery):
+			return [][]byte{fixture(t, "challenge_rotated")}
+		case bytes.HasSuffix(req, rotatedChallenge):
+			return [][]byte{fixture(t, "challenge")}
+		case bytes.HasSuffix(req, challenge):
+			return [][]byte{fixture(t, "info")}
+		}
+		return nil
+	})
+	c := newTestClient(t, addr)
+
+	info, err := c.QueryInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "Synthetic Server", info.Name)
+}
+
+func TestQueryChallengeLoop(t *testing.T) {
+	var requests atomic.Int32
+	addr := replay(t, func(req []byte) [][]byte {
+		requests.Add(1)
+		return [][]byte{fixture(t, "challenge")}
+	})
+	c := newTestClient(t, addr)
+
+	_, err := c.QueryPlayers()
+	assert.ErrorIs(t, err, ErrChallenge)
+	assert.Equal(t, int32(MaxChallengeRetries+1), requests.Load())
+}
+
+func TestQueryPlayersSplit(t *testing.T) {
+	addr := replay(t, withChallenge(t, playerQuery, func() [][]byte {
+		// Out of order, with a duplicate and a packet of another response
+		return [][]byte{
+			fixture(t, "players_split_2"),
+			fixture(t, "rules_bz2_1"),
+			fixture(t, "players_split_0"),
+			fixture(t, "players_split_2"),
+			fixture(t, "players_split_1"),
+		}
+	}))
+	c := newTestClient(t, addr)
+
+	players, err := c.QueryPlayers()
+	require.NoError(t, err)
+	require.Len(t, players, 20)
+	assert.Equal(t, Player{Name: "Player 00 with a long name"}, players[0])
+	assert.Equal(t, Player{Name: "Player 19 with a long name", Score: 57, Duration: 1140}, players[19])
+}
+
+func TestQueryRulesCompressed(t *testing.T) {
+	addr := replay(t, withChallenge(t, rulesQuery, func() [][]byte {
+		return [][]byte{fixture(t, "rules_bz2_1"), fixture(t, "rules_bz2_0")}
+	}))
+	c := newTestClient(t, addr)
+
+	rules, err := c.QueryRules()
+	require.NoError(t, err)
+	assert.Len(t, rules, 60)
+	assert.Equal(t, "value 0", rules["sv_rule_00"])
+	assert.Equal(t, "value 3481", rules["sv_rule_59"])
+}
+
+func TestQueryRulesBadChecksum(t *testing.T) {
+	addr := replay(t, withChallenge(t, rulesQuery, func() [][]byte {
+		first := fixture(t, "rules_bz2_0")
+		// Checksum follows the split header and the decompressed size
+		first[16] ^= 0xFF
+		return [][]byte{first, fixture(t, "rules_bz2_1")}
+	}))
+	c := newTestClient(t, addr)
+
+	_, err := c.QueryRules()
+	assert.ErrorIs(t, err, ErrBadChecksum)
+}
+
+func TestQueryInfoGoldSrc(t *testing.T) {
+	// Obsolete GoldSrc info response with mod info, sent as two GoldSrc split packets
+	info := []byte("\xFF\xFF\xFF\xFFm127.0.0.1:27015\x00Retro CS\x00de_nuke\x00cstrike\x00Counter-Strike\x00")
+	info = append(info, 2, 16, 47, 'd', 'l', 0, 1)
+	info = append(info, "http://example.com\x00\x00"...)
+	info = append(info, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	info = append(info, 1, 1)
+	split := []byte{0xFE, 0xFF, 0xFF, 0xFF, 7, 0, 0, 0}
+
+	addr := replay(t, withChallenge(t, infoQuery, func() [][]byte {
+		return [][]byte{
+			append(append(bytes.Clone(split), 0x12), info[30:]...),
+			append(append(bytes.Clone(split), 0x02), info[:30]...),
+		}
+	}))
+	c, err := NewGoldSrcClient(addr, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	got, err := c.QueryInfo()
+	require.NoError(t, err)
+	assert.Equal(t, &Info{
+		Protocol:    47,
+		Name:        "Retro CS",
+		Map:         "de_nuke",
+		Folder:      "cstrike",
+		Game:        "Counter-Strike",
+		Players:     2,
+		MaxPlayers:  16,
+		Bots:        1,
+		ServerType:  'd',
+		Environment: 'l',
+		VAC:         true,
+	}, got)
+
+	_, err = parseGoldSrcInfo([]byte("truncated"))
+	assert.ErrorIs(t, err, errShort)
+}
+
+func TestExchange(t *testing.T) {
+	addr := replay(t, func(req []byte) [][]byte {
+		if !bytes.Equal(req, []byte("\xFF\xFF\xFF\xFFchallenge rcon\n")) {
+			return nil
+		}
+		return [][]byte{
+			[]byte("\xFF\xFF\xFF\xFFchallenge rcon 1234\n\x00"),
+			[]byte("\xFF\xFF\xFF\xFFlfollow-up\x00"),
+		}
+	})
+	c, err := NewGoldSrcClient(addr, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	resp, err := c.Exchange([]byte("challenge rcon\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("challenge rcon 1234\n\x00"), resp)
+
+	resp, err = c.Receive(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("lfollow-up\x00"), resp)
+
+	_, err = c.Receive(10 * time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	// A packet of the split response is lost
+	addr := replay(t, withChallenge(t, playerQuery, func() [][]byte {
+		return [][]byte{fixture(t, "players_split_0"), fixture(t, "players_split_2")}
+	}))
+	c := newTestClient(t, addr)
+
+	start := time.Now()
+	_, err := c.QueryPlayers()
+	assert.ErrorContains(t, err, "received 2 of 3 split packets")
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package a2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// errShort a response ended before all of its fields were read
+var errShort = errors.New("a2s: response is truncated")
+
+// Info A2S_INFO response
+type Info struct {
+	Protocol    byte
+	Name        string
+	Map         string
+	Folder      string
+	Game        string
+	AppID       uint16
+	Players     byte
+	MaxPlayers  byte
+	Bots        byte
+	ServerType  byte
+	Environment byte
+	Visibility  bool
+	VAC         bool
+	Version     string
+
+	// Extra data, only set when the server sends it
+	Port     uint16
+	SteamID  uint64
+	Keywords string
+	GameID   uint64
+}
+
+// Player A2S_PLAYER response entry
+type Player struct {
+	Index    byte
+	Name     string
+	Score    int32
+	Duration float32
+}
+
+// reader reads the little endian fields of a response
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = errShort
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) byte() byte {
+	return r.next(1)[0]
+}
+
+func (r *reader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.next(2))
+}
+
+func (r *reader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *reader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data, 0)
+	if i < 0 {
+		r.err = errShort
+		return ""
+	}
+	s := string(r.data[:i])
+	r.data = r.data[i+1:]
+	return s
+}
+
+// parseInfo parses an A2S_INFO response body
+func parseInfo(data []byte) (*Info, error) {
+	r := &reader{data: data}
+	info := &Info{
+		Protocol:    r.byte(),
+		Name:        r.string(),
+		Map:         r.string(),
+		Folder:      r.string(),
+		Game:        r.string(),
+		AppID:       r.uint16(),
+		Players:     r.byte(),
+		MaxPlayers:  r.byte(),
+		Bots:        r.byte(),
+		ServerType:  r.byte(),
+		Environment: r.byte(),
+		Visibility:  r.byte() == 1,
+		VAC:         r.byte() == 1,
+	}
+	// The Ship sends its game mode, witnesses and duration here
+	if info.AppID == 2400 {
+		r.next(3)
+	}
+	info.Version = r.string()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// Extra data flag, older servers end the response here
+	if len(r.data) == 0 {
+		return info, nil
+	}
+	edf := r.byte()
+	if edf&0x80 != 0 {
+		info.Port = r.uint16()
+	}
+	if edf&0x10 != 0 {
+		info.SteamID = r.uint64()
+	}
+	if edf&0x40 != 0 {
+		// SourceTV port and name
+		r.uint16()
+		r.string()
+	}
+	if edf&0x20 != 0 {
+		info.Keywords = r.string()
+	}
+	if edf&0x01 != 0 {
+		info.GameID = r.uint64()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return info, nil
+}
+
+// parseGoldSrcInfo parses an A2S_INFO response body in the obsolete GoldSrc
+// format, the server's version is not part of it
+func parseGoldSrcInfo(data []byte) (*Info, error) {
+	r := &reader{data: data}
+	// Address of the server
+	r.string()
+	info := &Info{
+		Name:        r.string(),
+		Map:         r.string(),
+		Folder:      r.string(),
+		Game:        r.string(),
+		Players:     r.byte(),
+		MaxPlayers:  r.byte(),
+		Protocol:    r.byte(),
+		ServerType:  r.byte(),
+		Environment: r.byte(),
+		Visibility:  r.byte() == 1,
+	}
+	if mod := r.byte(); mod == 1 {
+		// Link, download link, null byte, version, size, type and DLL of the mod
+		r.string()
+		r.string()
+		r.next(1 + 4 + 4 + 1 + 1)
+	}
+	info.VAC = r.byte() == 1
+	info.Bots = r.byte()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return info, nil
+}
+
+// parsePlayers parses an A2S_PLAYER response body
+func parsePlayers(data []byte) ([]Player, error) {
+	r := &reader{data: data}
+	count := int(r.byte())
+	players := make([]Player, 0, count)
+	for range count {
+		p := Player{
+			Index:    r.byte(),
+			Name:     r.string(),
+			Score:    int32(r.uint32()),
+			Duration: math.Float32frombits(r.uint32()),
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		players = append(players, p)
+	}
+	return players, nil
+}
+
+// parseRules parses an A2S_RULES response body. Some games truncate long
+// rule lists, the rules read until then are returned.
+func parseRules(data []byte) (map[string]string, error) {
+	r := &reader{data: data}
+	count := int(r.uint16())
+	if r.err != nil {
+		return nil, r.err
+	}
+	rules := make(map[string]string, count)
+	for range count {
+		name, value := r.string(), r.string()
+		if r.err != nil {
+			break
+		}
+		rules[name] = value
+	}
+	return rules, nil
+}
@@ -20,13 +20,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/galexrt/srcds_exporter/connector/a2s"
 	"github.com/galexrt/srcds_exporter/parser/models"
 	"github.com/patrickmn/go-cache"
-	a2s "github.com/rumblefrog/go-a2s"
 	"github.com/sirupsen/logrus"
 )
 
-// A2S is a connection using the Valve A2S query protocol (via go-a2s)
+// A2S is a connection using the Valve A2S query protocol (see the a2s package)
 type A2S struct {
 	log     *logrus.Entry
 	opts    *ConnectionOptions
@@ -34,20 +34,27 @@ type A2S struct {
 	client  *a2s.Client
 	cmu     sync.Mutex
 	created time.Time
+	// rules whether the server rules are queried for snapshots
+	rules bool
 }
 
 // NewA2S creates a new A2S based IConnection
 func NewA2S(name string, opts *ConnectionOptions, log *logrus.Logger) IConnection {
+	return newA2S(name, opts, log, true)
+}
+
+func newA2S(name string, opts *ConnectionOptions, log *logrus.Logger, rules bool) *A2S {
 	return &A2S{
 		log:     log.WithFields(logrus.Fields{"server": name}),
 		opts:    opts,
 		cache:   cache.New(opts.CacheExpiration, opts.CacheCleanupInterval),
 		created: time.Time{},
+		rules:   rules,
 	}
 }
 
 func (c *A2S) Reconnect() error {
-	client, err := a2s.NewClient(c.opts.Addr, a2s.TimeoutOption(c.opts.ConnectTimeout))
+	client, err := a2s.NewClient(c.opts.Addr, c.opts.ConnectTimeout)
 	if err != nil {
 		return err
 	}
@@ -75,25 +82,52 @@ func (c *A2S) ensureConnected() error {
 	return nil
 }
 
-func (c *A2S) getInfo() (*a2s.ServerInfo, error) {
+func (c *A2S) getInfo() (*a2s.Info, error) {
 	c.cmu.Lock()
 	defer c.cmu.Unlock()
 
 	out, found := c.cache.Get("info")
 	if !found {
-		if err := c.ensureConnected(); err != nil {
-			return nil, err
-		}
-
-		info, err := c.client.QueryInfo()
+		info, err := c.queryInfo()
 		if err != nil {
 			return nil, err
 		}
-		c.cache.Add("info", info, cache.DefaultExpiration)
 		out = info
 	}
 
-	return out.(*a2s.ServerInfo), nil
+	return out.(*a2s.Info), nil
+}
+
+// queryInfo queries the server info and caches it, c.cmu must be held
+func (c *A2S) queryInfo() (*a2s.Info, error) {
+	if err := c.ensureConnected(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.client.QueryInfo()
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Set("info", info, cache.DefaultExpiration)
+
+	return info, nil
+}
+
+// queryPlayers queries the players and caches them, c.cmu must be held
+func (c *A2S) queryPlayers() (map[string]*models.Player, error) {
+	if err := c.ensureConnected(); err != nil {
+		return nil, err
+	}
+
+	playerInfo, err := c.client.QueryPlayers()
+	if err != nil {
+		return nil, err
+	}
+
+	players := a2sPlayers(playerInfo)
+	c.cache.Set("players", players, cache.DefaultExpiration)
+
+	return players, nil
 }
 
 // GetMap return map of server
@@ -113,12 +147,8 @@ func (c *A2S) GetPlayerCount() (*models.PlayerCount, error) {
 		return nil, err
 	}
 
-	return &models.PlayerCount{
-		Current: int(info.Players),
-		Max:     int(info.MaxPlayers),
-		Bots:    int(info.Bots),
-		Humans:  int(info.Players) - int(info.Bots),
-	}, nil
+	playerCount := a2sPlayerCount(info)
+	return &playerCount, nil
 }
 
 // GetPlayers return the players connected to the server.
@@ -131,25 +161,73 @@ func (c *A2S) GetPlayers() (map[string]*models.Player, error) {
 
 	out, found := c.cache.Get("players")
 	if !found {
-		if err := c.ensureConnected(); err != nil {
-			return nil, err
-		}
-
-		playerInfo, err := c.client.QueryPlayer()
+		players, err := c.queryPlayers()
 		if err != nil {
 			return nil, err
 		}
-
-		players := make(map[string]*models.Player, len(playerInfo.Players))
-		for _, p := range playerInfo.Players {
-			players[p.Name] = &models.Player{
-				Username: p.Name,
-				UserID:   int(p.Index),
-			}
-		}
-		c.cache.Add("players", players, cache.DefaultExpiration)
 		out = players
 	}
 
 	return out.(map[string]*models.Player), nil
 }
+
+// GetSnapshot return a snapshot of the server info, players and rules.
+//
+// All three are queried freshly, so they aren't mixed with older cached data.
+// Rules are left nil when the server doesn't answer A2S_RULES queries or
+// rules aren't queried by the connection.
+func (c *A2S) GetSnapshot() (*models.Snapshot, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+
+	info, err := c.queryInfo()
+	if err != nil {
+		return nil, err
+	}
+	players, err := c.queryPlayers()
+	if err != nil {
+		return nil, err
+	}
+
+	var rules map[string]string
+	if c.rules {
+		if rules, err = c.client.QueryRules(); err != nil {
+			c.log.Debugf("failed to query rules: %s", err)
+			rules = nil
+		}
+	}
+
+	return &models.Snapshot{
+		Time: time.Now(),
+		Status: models.Status{
+			Hostname:    info.Name,
+			Version:     info.Version,
+			Map:         info.Map,
+			PlayerCount: a2sPlayerCount(info),
+		},
+		Players: players,
+		Rules:   rules,
+	}, nil
+}
+
+// a2sPlayerCount return the player count of an A2S info response
+func a2sPlayerCount(info *a2s.Info) models.PlayerCount {
+	return models.PlayerCount{
+		Current: int(info.Players),
+		Max:     int(info.MaxPlayers),
+		Bots:    int(info.Bots),
+		Humans:  int(info.Players) - int(info.Bots),
+	}
+}
+
+// a2sPlayers return the players of an A2S player response keyed by name
+func a2sPlayers(in []a2s.Player) map[string]*models.Player {
+	players := make(map[string]*models.Player, len(in))
+	for _, p := range in {
+		players[p.Name] = &models.Player{
+			Username: p.Name,
+			UserID:   int(p.Index),
+		}
+	}
+	return players
+}
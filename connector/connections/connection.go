@@ -27,7 +27,7 @@ import (
 type ConnectionOptions struct {
 	Addr                 string
 	Mode                 config.QueryMode
-	RCONPassword         string
+	RCONPassword         config.Secret
 	ConnectTimeout       time.Duration
 	CacheExpiration      time.Duration
 	CacheCleanupInterval time.Duration
@@ -39,4 +39,21 @@ type IConnection interface {
 	GetMap() (string, error)
 	GetPlayerCount() (*models.PlayerCount, error)
 	GetPlayers() (map[string]*models.Player, error)
+	// GetSnapshot fetches status, players and rules of the server in one go
+	GetSnapshot() (*models.Snapshot, error)
+}
+
+// Degradable is implemented by connections which fall back to a reduced set
+// of data when part of the server can't be queried
+type Degradable interface {
+	// Degraded returns true while the connection serves the reduced set of data
+	Degraded() bool
+}
+
+// CommandRunner is implemented by connections which can run console commands
+// on the server via RCON
+type CommandRunner interface {
+	// RunCommand runs the command and returns its output, outputs are cached
+	// for the cache expiration like the `status` output
+	RunCommand(cmd string) (string, error)
 }
@@ -0,0 +1,198 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeA2SInfo info served by fakeA2S
+type fakeA2SInfo struct {
+	Name       string
+	Map        string
+	Players    []string
+	MaxPlayers byte
+	Bots       byte
+}
+
+// fakeA2S Source server stand-in answering A2S info, player and rules queries
+type fakeA2S struct {
+	conn net.PacketConn
+
+	mu   sync.Mutex
+	info fakeA2SInfo
+}
+
+// newFakeA2S listens on a UDP port of 127.0.0.1, the TCP port of the same
+// number can be used by fakeRCON
+func newFakeA2S(t *testing.T, addr string, info fakeA2SInfo) *fakeA2S {
+	conn, err := net.ListenPacket("udp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	f := &fakeA2S{conn: conn, info: info}
+	go f.serve()
+	return f
+}
+
+func (f *fakeA2S) serve() {
+	header := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n < 5 || !bytes.HasPrefix(buf, header) {
+			continue
+		}
+
+		f.mu.Lock()
+		info := f.info
+		f.mu.Unlock()
+
+		resp := append([]byte{}, header...)
+		switch buf[4] {
+		case 'T':
+			resp = append(resp, 'I', 17)
+			resp = append(resp, info.Name+"\x00"+info.Map+"\x00cstrike\x00Counter-Strike: Source\x00"...)
+			resp = binary.LittleEndian.AppendUint16(resp, 240)
+			resp = append(resp, byte(len(info.Players)), info.MaxPlayers, info.Bots, 'd', 'l', 0, 1)
+			resp = append(resp, "1.0.0.0\x00"...)
+		case 'U':
+			resp = append(resp, 'D', byte(len(info.Players)))
+			for _, name := range info.Players {
+				resp = append(resp, 0)
+				resp = append(resp, name+"\x00"...)
+				resp = binary.LittleEndian.AppendUint32(resp, 0)
+				resp = binary.LittleEndian.AppendUint32(resp, math.Float32bits(60))
+			}
+		case 'V':
+			resp = append(resp, 'E', 0, 0)
+		default:
+			continue
+		}
+		f.conn.WriteTo(resp, addr)
+	}
+}
+
+// fakeRCON Source RCON server stand-in
+type fakeRCON struct {
+	l net.Listener
+
+	mu       sync.Mutex
+	password string
+	// responses output per command
+	responses map[string]string
+	// auths number of authentication attempts
+	auths int
+}
+
+func newFakeRCON(t *testing.T, addr string, password string, responses map[string]string) *fakeRCON {
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	f := &fakeRCON{l: l, password: password, responses: responses}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.handle(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRCON) handle(conn net.Conn) {
+	defer conn.Close()
+
+	write := func(id int32, typ int32, body []byte) {
+		packet := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+10))
+		packet = binary.LittleEndian.AppendUint32(packet, uint32(id))
+		packet = binary.LittleEndian.AppendUint32(packet, uint32(typ))
+		packet = append(packet, body...)
+		packet = append(packet, 0, 0)
+		conn.Write(packet)
+	}
+
+	for {
+		var size int32
+		if err := binary.Read(conn, binary.LittleEndian, &size); err != nil {
+			return
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return
+		}
+		id := int32(binary.LittleEndian.Uint32(data[0:4]))
+		typ := int32(binary.LittleEndian.Uint32(data[4:8]))
+		body := string(bytes.TrimRight(data[8:], "\x00"))
+
+		f.mu.Lock()
+		password := f.password
+		response := f.responses[body]
+		f.mu.Unlock()
+
+		switch typ {
+		case 3:
+			f.mu.Lock()
+			f.auths++
+			f.mu.Unlock()
+			write(id, 0, nil)
+			if body != password {
+				write(-1, 2, nil)
+				return
+			}
+			write(id, 2, nil)
+		case 2:
+			write(id, 0, []byte(response))
+		case 0:
+			// Mirror packet, answered with an empty and the trailer packet
+			write(id, 0, nil)
+			write(id, 0, []byte{0x00, 0x01, 0x00, 0x00})
+		}
+	}
+}
+
+func (f *fakeRCON) setPassword(password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.password = password
+}
+
+func (f *fakeRCON) setResponse(cmd string, response string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[cmd] = response
+}
+
+func (f *fakeRCON) authAttempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.auths
+}
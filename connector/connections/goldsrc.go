@@ -0,0 +1,271 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/connector/a2s"
+	"github.com/galexrt/srcds_exporter/parser"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/patrickmn/go-cache"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// goldSrcFollowUpWait time to wait for further packets of a long RCON response
+	goldSrcFollowUpWait = 100 * time.Millisecond
+	// goldSrcFullPacketSize responses of at least this size may be continued in another packet
+	goldSrcFullPacketSize = 1000
+
+	goldSrcPrintResponse = 'l'
+)
+
+var (
+	// ErrGoldSrcBadPassword the server rejected the RCON password
+	ErrGoldSrcBadPassword  = errors.New("goldsrc: bad rcon password")
+	errGoldSrcBadChallenge = errors.New("goldsrc: bad rcon challenge")
+)
+
+// GoldSrcRCON is a connection to GoldSrc (HLDS) servers, using the UDP RCON
+// protocol for the `status` command and the A2S info query (see the a2s
+// package)
+type GoldSrcRCON struct {
+	log    *logrus.Entry
+	opts   *ConnectionOptions
+	cache  *cache.Cache
+	client *a2s.Client
+	// challenge RCON challenge of the server, requested on first use
+	challenge string
+	cmu       sync.Mutex
+}
+
+// NewGoldSrcRCON creates a new GoldSrc RCON based IConnection
+func NewGoldSrcRCON(name string, opts *ConnectionOptions, log *logrus.Logger) IConnection {
+	return &GoldSrcRCON{
+		log:   log.WithFields(logrus.Fields{"server": name}),
+		opts:  opts,
+		cache: cache.New(opts.CacheExpiration, opts.CacheCleanupInterval),
+	}
+}
+
+func (c *GoldSrcRCON) Reconnect() error {
+	client, err := a2s.NewGoldSrcClient(c.opts.Addr, c.opts.ConnectTimeout)
+	if err != nil {
+		return err
+	}
+
+	if c.client != nil {
+		c.client.Close()
+	}
+	c.client = client
+	c.challenge = ""
+
+	return nil
+}
+
+// Close closes the UDP socket
+func (c *GoldSrcRCON) Close() {
+	if c.client != nil {
+		c.client.Close()
+	}
+}
+
+func (c *GoldSrcRCON) ensureConnected() error {
+	if c.client == nil {
+		return c.Reconnect()
+	}
+	return nil
+}
+
+// GetMap return map of server
+func (c *GoldSrcRCON) GetMap() (string, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+
+	info, err := c.getInfo()
+	if err != nil {
+		return "", err
+	}
+	return info.Map, nil
+}
+
+// GetPlayerCount return server player count
+func (c *GoldSrcRCON) GetPlayerCount() (*models.PlayerCount, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+
+	info, err := c.getInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &info.PlayerCount, nil
+}
+
+// GetPlayers return the players parsed from the `status` command
+func (c *GoldSrcRCON) GetPlayers() (map[string]*models.Player, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+
+	resp, err := c.getStatus()
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseGoldSrcPlayers(resp), nil
+}
+
+// GetSnapshot return a snapshot of the server from the info query and the `status` command
+func (c *GoldSrcRCON) GetSnapshot() (*models.Snapshot, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+
+	c.cache.Delete("info")
+	c.cache.Delete("status")
+	info, err := c.getInfo()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.getStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	status := *info
+	if parsed, err := parser.ParseGoldSrcStatus(resp); err == nil {
+		status.Version = parsed.Version
+	}
+
+	return &models.Snapshot{
+		Time:            time.Now(),
+		Status:          status,
+		Players:         parser.ParseGoldSrcPlayers(resp),
+		PlayersFromRCON: true,
+	}, nil
+}
+
+// getInfo return the cached or freshly queried server info, c.cmu must be held
+func (c *GoldSrcRCON) getInfo() (*models.Status, error) {
+	if out, found := c.cache.Get("info"); found {
+		return out.(*models.Status), nil
+	}
+	if err := c.ensureConnected(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.client.QueryInfo()
+	if err != nil {
+		return nil, err
+	}
+	status := &models.Status{
+		Hostname:    info.Name,
+		Version:     info.Version,
+		Map:         info.Map,
+		PlayerCount: a2sPlayerCount(info),
+	}
+	c.cache.Set("info", status, cache.DefaultExpiration)
+
+	return status, nil
+}
+
+// RunCommand implements the CommandRunner interface
+func (c *GoldSrcRCON) RunCommand(cmd string) (string, error) {
+	c.cmu.Lock()
+	defer c.cmu.Unlock()
+	return c.getCommand(cmd)
+}
+
+// getStatus return the cached or freshly requested `status` output, c.cmu must be held
+func (c *GoldSrcRCON) getStatus() (string, error) {
+	return c.getCommand("status")
+}
+
+// getCommand return the cached or freshly requested output of the command,
+// c.cmu must be held
+func (c *GoldSrcRCON) getCommand(cmd string) (string, error) {
+	key := "command/" + cmd
+	if out, found := c.cache.Get(key); found {
+		return out.(string), nil
+	}
+	if err := c.ensureConnected(); err != nil {
+		return "", err
+	}
+
+	resp, err := c.runRCONCommand(cmd)
+	// The challenge changes when the server restarts or the map changes
+	if errors.Is(err, errGoldSrcBadChallenge) {
+		c.challenge = ""
+		resp, err = c.runRCONCommand(cmd)
+	}
+	if err != nil {
+		return "", err
+	}
+	c.cache.Set(key, resp, cache.DefaultExpiration)
+
+	return resp, nil
+}
+
+// runRCONCommand runs the command via UDP RCON and returns the output
+func (c *GoldSrcRCON) runRCONCommand(cmd string) (string, error) {
+	if c.challenge == "" {
+		resp, err := c.client.Exchange([]byte("challenge rcon\n"))
+		if err != nil {
+			return "", fmt.Errorf("goldsrc: failed to get rcon challenge: %w", err)
+		}
+		fields := strings.Fields(strings.TrimRight(string(resp), "\x00"))
+		if len(fields) != 3 || fields[0] != "challenge" || fields[1] != "rcon" {
+			return "", fmt.Errorf("goldsrc: invalid rcon challenge response %q", resp)
+		}
+		c.challenge = fields[2]
+	}
+
+	resp, err := c.client.Exchange(fmt.Appendf(nil, "rcon %s \"%s\" %s\n", c.challenge, string(c.opts.RCONPassword), cmd))
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.Write(trimGoldSrcPrint(resp))
+	// Long outputs are sent in multiple packets without marking the last one
+	for len(resp) >= goldSrcFullPacketSize {
+		if resp, err = c.client.Receive(goldSrcFollowUpWait); err != nil {
+			break
+		}
+		out.Write(trimGoldSrcPrint(resp))
+	}
+
+	text := out.String()
+	switch {
+	case strings.HasPrefix(text, "Bad rcon_password"):
+		return "", ErrGoldSrcBadPassword
+	case strings.HasPrefix(text, "Bad challenge"):
+		return "", errGoldSrcBadChallenge
+	}
+	return text, nil
+}
+
+// trimGoldSrcPrint returns the text of a print response
+func trimGoldSrcPrint(resp []byte) []byte {
+	if len(resp) > 0 && resp[0] == goldSrcPrintResponse {
+		resp = resp[1:]
+	}
+	return bytes.TrimRight(resp, "\x00")
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeGoldSrcStatus = `hostname:  Retro CS
+version :  48/1.1.2.7/Stdio 8684 secure  (10)
+tcp/ip  :  127.0.0.1:27015
+map     :  de_nuke at: 0 x, 0 y, 0 z
+players :  2 active (16 max)
+
+#      name userid uniqueid frag time ping loss adr
+# 1 "Player" 2 STEAM_0:0:1234  3  1:00   30    0 127.0.0.1:27005
+# 2 "Bot" 3 BOT   0  1:00    0    0
+2 users
+`
+
+// fakeGoldSrc GoldSrc server stand-in answering info queries and UDP RCON
+type fakeGoldSrc struct {
+	conn net.PacketConn
+
+	mu sync.Mutex
+	// challenge current RCON challenge, changed by the tests to simulate a map change
+	challenge int
+	commands  []string
+}
+
+func newFakeGoldSrc(t *testing.T) *fakeGoldSrc {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	f := &fakeGoldSrc{conn: conn, challenge: 1234}
+	go f.serve()
+	return f
+}
+
+func (f *fakeGoldSrc) serve() {
+	header := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	infoChallenge := []byte{0x0A, 0x0B, 0x0C, 0x0D}
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[4:n]
+
+		f.mu.Lock()
+		challenge := f.challenge
+		f.mu.Unlock()
+
+		switch {
+		case bytes.HasPrefix(req, []byte("TSource Engine Query\x00")):
+			if !bytes.HasSuffix(req, infoChallenge) {
+				f.conn.WriteTo(append(append(append([]byte{}, header...), 'A'), infoChallenge...), addr)
+				continue
+			}
+			// Obsolete GoldSrc info response, sent as two split packets
+			info := append(append([]byte{}, header...), 'm')
+			info = append(info, "127.0.0.1:27015\x00Retro CS\x00de_nuke\x00cstrike\x00Counter-Strike\x00"...)
+			// Players, max players, protocol, type, environment, visibility, mod
+			info = append(info, 2, 16, 47, 'd', 'l', 0, 1)
+			// Mod link, download link, null byte, version, size, type, DLL
+			info = append(info, "http://example.com\x00\x00"...)
+			info = append(info, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+			// VAC, bots
+			info = append(info, 1, 1)
+			split := []byte{0xFE, 0xFF, 0xFF, 0xFF, 1, 0, 0, 0}
+			f.conn.WriteTo(append(append(append([]byte{}, split...), 0x12), info[20:]...), addr)
+			f.conn.WriteTo(append(append(append([]byte{}, split...), 0x02), info[:20]...), addr)
+		case string(req) == "challenge rcon\n":
+			f.conn.WriteTo(append(append([]byte{}, header...), fmt.Sprintf("challenge rcon %d\n\x00", challenge)...), addr)
+		case bytes.HasPrefix(req, []byte("rcon ")):
+			var got int
+			var password, cmd string
+			fmt.Sscanf(string(req), "rcon %d %q %s", &got, &password, &cmd)
+			f.mu.Lock()
+			f.commands = append(f.commands, cmd)
+			f.mu.Unlock()
+
+			out := ""
+			switch {
+			case got != challenge:
+				out = "Bad challenge.\n"
+			case password != "secret":
+				out = "Bad rcon_password.\n"
+			case cmd == "status":
+				out = fakeGoldSrcStatus
+			}
+			f.conn.WriteTo(append(append(append([]byte{}, header...), 'l'), out+"\x00"...), addr)
+		}
+	}
+}
+
+func TestGoldSrcRCONGetSnapshot(t *testing.T) {
+	f := newFakeGoldSrc(t)
+	// Responses aren't cached, so every call talks to the server
+	con := NewGoldSrcRCON("test", &ConnectionOptions{
+		Addr:                 f.conn.LocalAddr().String(),
+		RCONPassword:         "secret",
+		ConnectTimeout:       2 * time.Second,
+		CacheExpiration:      time.Nanosecond,
+		CacheCleanupInterval: time.Minute,
+	}, logrus.New())
+	defer con.Close()
+
+	snapshot, err := con.GetSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, models.Status{
+		Hostname: "Retro CS",
+		Version:  "48/1.1.2.7/Stdio 8684 secure  (10)",
+		Map:      "de_nuke",
+		PlayerCount: models.PlayerCount{
+			Current: 2,
+			Max:     16,
+			Humans:  1,
+			Bots:    1,
+		},
+	}, snapshot.Status)
+	assert.Len(t, snapshot.Players, 2)
+	assert.Equal(t, 30, snapshot.Players["STEAM_0:0:1234"].Ping)
+
+	// The challenge changes, e.g., on map change, and is requested again
+	f.mu.Lock()
+	f.challenge = 5678
+	f.mu.Unlock()
+	players, err := con.GetPlayers()
+	require.NoError(t, err)
+	assert.Len(t, players, 2)
+	f.mu.Lock()
+	assert.Equal(t, []string{"status", "status", "status"}, f.commands)
+	f.mu.Unlock()
+
+	mapName, err := con.GetMap()
+	require.NoError(t, err)
+	assert.Equal(t, "de_nuke", mapName)
+}
+
+func TestGoldSrcRCONBadPassword(t *testing.T) {
+	f := newFakeGoldSrc(t)
+	con := NewGoldSrcRCON("test", &ConnectionOptions{
+		Addr:                 f.conn.LocalAddr().String(),
+		RCONPassword:         "wrong",
+		ConnectTimeout:       2 * time.Second,
+		CacheExpiration:      time.Minute,
+		CacheCleanupInterval: time.Minute,
+	}, logrus.New())
+	defer con.Close()
+
+	_, err := con.GetPlayers()
+	assert.ErrorIs(t, err, ErrGoldSrcBadPassword)
+}
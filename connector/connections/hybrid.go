@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+)
+
+// HybridRCONRetryInterval time after which RCON is tried again when the
+// connection degraded because of a rejected RCON password
+var HybridRCONRetryInterval = 10 * time.Minute
+
+// ErrHybridDegraded RCON commands can't be run while the connection is degraded
+var ErrHybridDegraded = errors.New("hybrid: connection is degraded to A2S only, RCON is unavailable")
+
+// Hybrid is a connection which queries info, map and player count via A2S
+// and uses RCON only for the player details A2S lacks. When the server
+// rejects the RCON password the connection degrades to A2S only, instead of
+// failing, until RCON is retried after HybridRCONRetryInterval.
+type Hybrid struct {
+	log  *logrus.Entry
+	a2s  *A2S
+	rcon *RCON
+
+	mu sync.Mutex
+	// degradedAt time RCON authentication failed, zero when not degraded
+	degradedAt time.Time
+}
+
+// NewHybrid creates a new hybrid A2S and RCON based IConnection
+func NewHybrid(name string, opts *ConnectionOptions, log *logrus.Logger) IConnection {
+	return &Hybrid{
+		log:  log.WithFields(logrus.Fields{"server": name}),
+		a2s:  NewA2S(name, opts, log).(*A2S),
+		rcon: NewRCON(name, opts).(*RCON),
+	}
+}
+
+// Reconnect reconnects the A2S client, RCON is connected on use
+func (c *Hybrid) Reconnect() error {
+	c.a2s.cmu.Lock()
+	defer c.a2s.cmu.Unlock()
+	return c.a2s.Reconnect()
+}
+
+// Close closes the A2S and RCON connections
+func (c *Hybrid) Close() {
+	c.a2s.Close()
+	c.rcon.Close()
+}
+
+// Degraded implements the Degradable interface
+func (c *Hybrid) Degraded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.degradedAt.IsZero()
+}
+
+// RunCommand implements the CommandRunner interface, commands fail while the
+// connection is degraded
+func (c *Hybrid) RunCommand(cmd string) (string, error) {
+	c.mu.Lock()
+	degradedAt := c.degradedAt
+	c.mu.Unlock()
+	if !degradedAt.IsZero() && time.Since(degradedAt) < HybridRCONRetryInterval {
+		return "", ErrHybridDegraded
+	}
+	return c.rcon.RunCommand(cmd)
+}
+
+// GetMap return map of server via A2S
+func (c *Hybrid) GetMap() (string, error) {
+	return c.a2s.GetMap()
+}
+
+// GetPlayerCount return server player count via A2S
+func (c *Hybrid) GetPlayerCount() (*models.PlayerCount, error) {
+	return c.a2s.GetPlayerCount()
+}
+
+// GetPlayers return the players via RCON, falling back to A2S
+func (c *Hybrid) GetPlayers() (map[string]*models.Player, error) {
+	if snapshot := c.rconSnapshot(); snapshot != nil {
+		return snapshot.Players, nil
+	}
+	return c.a2s.GetPlayers()
+}
+
+// GetSnapshot return a snapshot of the server via A2S, with the players and
+// version from RCON if available
+func (c *Hybrid) GetSnapshot() (*models.Snapshot, error) {
+	snapshot, err := c.a2s.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	if rconSnapshot := c.rconSnapshot(); rconSnapshot != nil {
+		snapshot.Players = rconSnapshot.Players
+		snapshot.PlayersFromRCON = true
+		if rconSnapshot.Status.Version != "" {
+			snapshot.Status.Version = rconSnapshot.Status.Version
+		}
+	}
+
+	return snapshot, nil
+}
+
+// rconSnapshot return the RCON snapshot, nil when RCON failed or the
+// connection is degraded
+func (c *Hybrid) rconSnapshot() *models.Snapshot {
+	c.mu.Lock()
+	degradedAt := c.degradedAt
+	c.mu.Unlock()
+	if !degradedAt.IsZero() && time.Since(degradedAt) < HybridRCONRetryInterval {
+		return nil
+	}
+
+	snapshot, err := c.rcon.GetSnapshot()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case err == nil:
+		if !c.degradedAt.IsZero() {
+			c.log.Info("RCON authentication succeeded again, no longer degraded")
+			c.degradedAt = time.Time{}
+		}
+		return snapshot
+	case IsRCONAuthError(err):
+		if c.degradedAt.IsZero() {
+			c.log.Warnf("RCON authentication failed, degrading to A2S only for %s: %s", HybridRCONRetryInterval, err)
+		}
+		c.degradedAt = time.Now()
+	default:
+		c.log.Debugf("RCON failed, using A2S only: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeSourceStatus = `hostname: Test Server
+version : 7.0.0.0/24 1234 secure
+map     : de_dust2 at: 0 x, 0 y, 0 z
+players : 2 humans, 0 bots (16/0 max) (not hibernating)
+
+# userid name uniqueid connected ping loss state rate adr
+#      2 "Alice" STEAM_1:0:1 00:10 30 0 active 786432 127.0.0.1:27005
+#      3 "Bob" STEAM_1:0:2 00:10 40 0 active 786432 127.0.0.1:27006
+#end
+`
+
+// startFakeServer starts a RCON and A2S stand-in on the same port
+func startFakeServer(t *testing.T, password string) (*fakeRCON, *fakeA2S) {
+	rcon := newFakeRCON(t, "127.0.0.1:0", password, map[string]string{"status": fakeSourceStatus})
+	a2s := newFakeA2S(t, rcon.l.Addr().String(), fakeA2SInfo{
+		Name:       "Test Server",
+		Map:        "de_dust2",
+		Players:    []string{"Alice", "Bob"},
+		MaxPlayers: 16,
+	})
+	return rcon, a2s
+}
+
+func newTestHybrid(addr string, password string) *Hybrid {
+	return NewHybrid("test", &ConnectionOptions{
+		Addr:                 addr,
+		RCONPassword:         config.Secret(password),
+		ConnectTimeout:       2 * time.Second,
+		CacheExpiration:      time.Nanosecond,
+		CacheCleanupInterval: time.Minute,
+	}, logrus.New()).(*Hybrid)
+}
+
+func TestHybridUsesRCONForPlayers(t *testing.T) {
+	rcon, _ := startFakeServer(t, "secret")
+	con := newTestHybrid(rcon.l.Addr().String(), "secret")
+	defer con.Close()
+
+	snapshot, err := con.GetSnapshot()
+	require.NoError(t, err)
+	assert.False(t, con.Degraded())
+	assert.Equal(t, "Test Server", snapshot.Status.Hostname)
+	assert.Equal(t, "7.0.0.0/24 1234 secure", snapshot.Status.Version)
+	assert.Equal(t, models.PlayerCount{Current: 2, Max: 16, Humans: 2}, snapshot.Status.PlayerCount)
+	require.Contains(t, snapshot.Players, "STEAM_1:0:1")
+	assert.Equal(t, 30, snapshot.Players["STEAM_1:0:1"].Ping)
+}
+
+func TestHybridRCONFailure(t *testing.T) {
+	rcon, _ := startFakeServer(t, "secret")
+	con := newTestHybrid(rcon.l.Addr().String(), "secret")
+	defer con.Close()
+
+	snapshot, err := con.GetSnapshot()
+	require.NoError(t, err)
+	assert.True(t, snapshot.PlayersFromRCON)
+
+	// A single failed RCON scrape falls back to the A2S players, without
+	// degrading the connection
+	rcon.setResponse("status", "Unknown command \"status\"")
+	snapshot, err = con.GetSnapshot()
+	require.NoError(t, err)
+	assert.False(t, con.Degraded())
+	assert.False(t, snapshot.PlayersFromRCON)
+	assert.Contains(t, snapshot.Players, "Alice")
+	assert.NotContains(t, snapshot.Players, "STEAM_1:0:1")
+
+	rcon.setResponse("status", fakeSourceStatus)
+	snapshot, err = con.GetSnapshot()
+	require.NoError(t, err)
+	assert.True(t, snapshot.PlayersFromRCON)
+	assert.Contains(t, snapshot.Players, "STEAM_1:0:1")
+}
+
+func TestHybridDegradesOnRCONAuthFailure(t *testing.T) {
+	retryInterval := HybridRCONRetryInterval
+	defer func() { HybridRCONRetryInterval = retryInterval }()
+	HybridRCONRetryInterval = time.Hour
+
+	rcon, _ := startFakeServer(t, "secret")
+	con := newTestHybrid(rcon.l.Addr().String(), "wrong")
+	defer con.Close()
+
+	// Player counts and the A2S players are still available
+	snapshot, err := con.GetSnapshot()
+	require.NoError(t, err)
+	assert.True(t, con.Degraded())
+	assert.Equal(t, 2, snapshot.Status.PlayerCount.Current)
+	assert.Contains(t, snapshot.Players, "Alice")
+	assert.False(t, snapshot.PlayersFromRCON)
+
+	// RCON isn't retried until the retry interval passed
+	_, err = con.GetSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, 1, rcon.authAttempts())
+
+	// The server accepts the password again
+	rcon.setPassword("wrong")
+	HybridRCONRetryInterval = 0
+	snapshot, err = con.GetSnapshot()
+	require.NoError(t, err)
+	assert.False(t, con.Degraded())
+	assert.Contains(t, snapshot.Players, "STEAM_1:0:1")
+}
+
+func TestHybridRunCommand(t *testing.T) {
+	retryInterval := HybridRCONRetryInterval
+	defer func() { HybridRCONRetryInterval = retryInterval }()
+	HybridRCONRetryInterval = time.Hour
+
+	rcon, _ := startFakeServer(t, "secret")
+	con := newTestHybrid(rcon.l.Addr().String(), "secret")
+	defer con.Close()
+
+	out, err := con.RunCommand("status")
+	require.NoError(t, err)
+	assert.Equal(t, fakeSourceStatus, out)
+
+	// Commands aren't sent while degraded
+	rcon.setPassword("changed")
+	con.rcon.Close()
+	con.rcon.created = time.Time{}
+	_, err = con.GetSnapshot()
+	require.NoError(t, err)
+	require.True(t, con.Degraded())
+	_, err = con.RunCommand("status")
+	assert.ErrorIs(t, err, ErrHybridDegraded)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package connections
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -44,7 +45,7 @@ func NewRCON(name string, opts *ConnectionOptions) IConnection {
 
 func (c *RCON) Reconnect() error {
 	rcon, err := rcon.Connect(c.opts.Addr, &rcon.ConnectOptions{
-		RCONPassword: c.opts.RCONPassword,
+		RCONPassword: string(c.opts.RCONPassword),
 		Timeout:      c.opts.ConnectTimeout,
 	})
 	if err != nil {
@@ -57,7 +58,9 @@ func (c *RCON) Reconnect() error {
 
 // Close closes the RCON connection
 func (c *RCON) Close() {
-	c.rcon.Close()
+	if c.rcon != nil {
+		c.rcon.Close()
+	}
 }
 
 // runRCONCommand run rcon command and return response
@@ -81,6 +84,11 @@ func (c *RCON) runRCONCommand(cmd string) (string, error) {
 	return out.(string), nil
 }
 
+// RunCommand implements the CommandRunner interface
+func (c *RCON) RunCommand(cmd string) (string, error) {
+	return c.runRCONCommand(cmd)
+}
+
 // GetMap return map of server
 func (c *RCON) GetMap() (string, error) {
 	resp, err := c.runRCONCommand("status")
@@ -118,3 +126,39 @@ func (c *RCON) GetPlayers() (map[string]*models.Player, error) {
 
 	return players, nil
 }
+
+// GetSnapshot return a snapshot of the server parsed from a single `status` output
+func (c *RCON) GetSnapshot() (*models.Snapshot, error) {
+	resp, err := c.runRCONCommand("status")
+	if err != nil {
+		return nil, err
+	}
+
+	playerCount, err := parser.ParsePlayerCount(resp)
+	if err != nil {
+		return nil, err
+	}
+	// An empty server has no player lines in its `status` output
+	players, err := parser.ParsePlayers(resp)
+	if err != nil {
+		players = map[string]*models.Player{}
+	}
+
+	return &models.Snapshot{
+		Time: time.Now(),
+		Status: models.Status{
+			Hostname:    parser.ParseHostname(resp),
+			Version:     parser.ParseVersion(resp),
+			Map:         parser.ParseMap(resp),
+			PlayerCount: *playerCount,
+		},
+		Players:         players,
+		PlayersFromRCON: true,
+	}, nil
+}
+
+// IsRCONAuthError returns true if the error is caused by the server rejecting the RCON password
+func IsRCONAuthError(err error) bool {
+	// The rcon package wraps the error as text, so it can only be matched by its message
+	return err != nil && strings.Contains(err.Error(), rcon.ErrRCONAuthFailed.Error())
+}
@@ -17,99 +17,18 @@ limitations under the License.
 package connections
 
 import (
-	"sync"
-	"time"
-
-	"github.com/galexrt/srcds_exporter/parser/models"
-	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
-	"github.com/xv-chang/rconGo/core"
 )
 
+// ServerQuery is a connection using the Source Server Query protocol, the
+// A2S connection without querying the server rules
 type ServerQuery struct {
-	log     *logrus.Entry
-	opts    *ConnectionOptions
-	cache   *cache.Cache
-	con     *core.ServerQuery
-	cmu     sync.Mutex
-	created time.Time
+	*A2S
 }
 
+// NewServerQuery creates a new Source Server Query based IConnection
 func NewServerQuery(name string, opts *ConnectionOptions, log *logrus.Logger) IConnection {
 	return &ServerQuery{
-		log:     log.WithFields(logrus.Fields{"server": name}),
-		opts:    opts,
-		cache:   cache.New(opts.CacheExpiration, opts.CacheCleanupInterval),
-		created: time.Time{},
-	}
-}
-
-func (c *ServerQuery) Reconnect() error {
-	if (time.Now().Unix() - c.created.Unix()) > 5 {
-		c.cmu.Lock()
-		defer c.cmu.Unlock()
-		if c.con != nil {
-			c.con.Close()
-		}
-
-		c.con = core.NewServerQuery(c.opts.Addr)
-		c.con.Conn.SetDeadline(time.Now().Add(c.opts.ConnectTimeout))
-		c.created = time.Now()
-	}
-
-	return nil
-}
-
-// Close closes the RCON connection
-func (c *ServerQuery) Close() {
-	c.con.Close()
-}
-
-func (c *ServerQuery) getInfo() *core.ServerInfo {
-	defer func() {
-		if err := recover(); err != nil {
-			c.log.Errorf("got panic while connecting to server, reconnecting: %s", err)
-		}
-	}()
-
-	out, found := c.cache.Get("data")
-	if !found {
-		c.Reconnect()
-
-		out = c.con.GetInfo()
-		c.cache.Add("data", out, cache.DefaultExpiration)
+		A2S: newA2S(name, opts, log, false),
 	}
-
-	return out.(*core.ServerInfo)
-}
-
-// GetMap return map of server
-func (c *ServerQuery) GetMap() (string, error) {
-	info := c.getInfo()
-	if info == nil {
-		return "", nil
-	}
-
-	return info.Map, nil
-}
-
-// GetPlayerCount return server player count
-func (c *ServerQuery) GetPlayerCount() (*models.PlayerCount, error) {
-	info := c.getInfo()
-	if info == nil {
-		return nil, nil
-	}
-
-	playerCount := &models.PlayerCount{
-		Current: int(info.Players),
-		Max:     int(info.MaxPlayers),
-		Bots:    int(info.Bots),
-		Humans:  int(info.Players),
-	}
-
-	return playerCount, nil
-}
-
-func (c *ServerQuery) GetPlayers() (map[string]*models.Player, error) {
-	return nil, nil
 }
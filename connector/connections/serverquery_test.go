@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connections
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServerQuery(addr string) IConnection {
+	return NewServerQuery("test", &ConnectionOptions{
+		Addr:                 addr,
+		ConnectTimeout:       500 * time.Millisecond,
+		CacheExpiration:      time.Nanosecond,
+		CacheCleanupInterval: time.Minute,
+	}, logrus.New())
+}
+
+func TestServerQuery(t *testing.T) {
+	f := newFakeA2S(t, "127.0.0.1:0", fakeA2SInfo{
+		Name:       "Test Server",
+		Map:        "cs_office",
+		Players:    []string{"Alice", "Bob", "BOT Carl"},
+		MaxPlayers: 24,
+		Bots:       1,
+	})
+	con := newTestServerQuery(f.conn.LocalAddr().String())
+	defer con.Close()
+
+	snapshot, err := con.GetSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, models.Status{
+		Hostname:    "Test Server",
+		Version:     "1.0.0.0",
+		Map:         "cs_office",
+		PlayerCount: models.PlayerCount{Current: 3, Max: 24, Humans: 2, Bots: 1},
+	}, snapshot.Status)
+	assert.Len(t, snapshot.Players, 3)
+	// Unlike A2S, the rules aren't queried
+	assert.Nil(t, snapshot.Rules)
+
+	players, err := con.GetPlayers()
+	require.NoError(t, err)
+	require.Contains(t, players, "Alice")
+	assert.Equal(t, "Alice", players["Alice"].Username)
+
+	mapName, err := con.GetMap()
+	require.NoError(t, err)
+	assert.Equal(t, "cs_office", mapName)
+}
+
+func TestServerQueryErrors(t *testing.T) {
+	// Nothing answers on the address
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	con := newTestServerQuery(addr)
+	defer con.Close()
+
+	_, err = con.GetMap()
+	assert.Error(t, err)
+	_, err = con.GetPlayerCount()
+	assert.Error(t, err)
+	_, err = con.GetPlayers()
+	assert.Error(t, err)
+	_, err = con.GetSnapshot()
+	assert.Error(t, err)
+}
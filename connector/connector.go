@@ -18,6 +18,7 @@ package connector
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/galexrt/srcds_exporter/config"
 	"github.com/galexrt/srcds_exporter/connector/connections"
@@ -29,46 +30,88 @@ type Connector struct {
 	log        *logrus.Logger
 	a2sEnabled bool
 
+	mu          sync.RWMutex
 	connections map[string]connections.IConnection
+	options     map[string]connections.ConnectionOptions
 }
 
 // NewConnector creates a new Connector object.
-// a2sEnabled define whether servers configured with mode "A2S" (--a2s flag) may be connected to.
+// a2sEnabled define whether servers configured with mode "A2S" or "hybrid" (--a2s flag) may be connected to.
 func NewConnector(log *logrus.Logger, a2sEnabled bool) *Connector {
 	return &Connector{
 		log:         log,
 		a2sEnabled:  a2sEnabled,
 		connections: make(map[string]connections.IConnection),
+		options:     make(map[string]connections.ConnectionOptions),
 	}
 }
 
-// GetConnections holds all connections and reconnects/reopens them if necessary
-func (cn *Connector) GetConnections() (map[string]connections.IConnection, error) {
-	return cn.connections, nil
+// GetConnections returns a copy of all connections keyed by address
+func (cn *Connector) GetConnections() map[string]connections.IConnection {
+	cn.mu.RLock()
+	defer cn.mu.RUnlock()
+
+	out := make(map[string]connections.IConnection, len(cn.connections))
+	for addr, con := range cn.connections {
+		out[addr] = con
+	}
+	return out
+}
+
+// RemoveConnection closes and removes the connection to the address
+func (cn *Connector) RemoveConnection(addr string) {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	if con, ok := cn.connections[addr]; ok {
+		con.Close()
+		delete(cn.connections, addr)
+		delete(cn.options, addr)
+	}
 }
 
-// NewConnection Add a new connection and initiates first contact connection
+// NewConnection Add a new connection and initiates first contact connection.
+// An existing connection to the same address is replaced when its options changed (e.g., the RCON password).
 func (cn *Connector) NewConnection(name string, opts *connections.ConnectionOptions) error {
-	if _, ok := cn.connections[opts.Addr]; ok {
-		return nil
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	if con, ok := cn.connections[opts.Addr]; ok {
+		if cn.options[opts.Addr] == *opts {
+			return nil
+		}
+		cn.log.WithFields(logrus.Fields{"server": name}).Info("connection options changed, replacing connection")
+		con.Close()
+		delete(cn.connections, opts.Addr)
+		delete(cn.options, opts.Addr)
+	}
+	if opts.Mode.RequiresA2S() && !cn.a2sEnabled {
+		return fmt.Errorf("server %q is configured with mode %q but A2S support is disabled, enable it with the --a2s flag", name, opts.Mode)
 	}
 	switch opts.Mode {
 	case config.RCONMode:
 		cn.connections[opts.Addr] = connections.NewRCON(name, opts)
 	case config.A2SMode:
-		if !cn.a2sEnabled {
-			return fmt.Errorf("server %q is configured with mode %q but A2S support is disabled, enable it with the --a2s flag", name, opts.Mode)
-		}
 		cn.connections[opts.Addr] = connections.NewA2S(name, opts, cn.log)
-	default:
+	case config.ServerQueryMode:
 		cn.connections[opts.Addr] = connections.NewServerQuery(name, opts, cn.log)
+	case config.GoldSrcRCONMode:
+		cn.connections[opts.Addr] = connections.NewGoldSrcRCON(name, opts, cn.log)
+	case config.HybridMode:
+		cn.connections[opts.Addr] = connections.NewHybrid(name, opts, cn.log)
+	default:
+		return fmt.Errorf("server %q is configured with unknown mode %q", name, opts.Mode)
 	}
+	cn.options[opts.Addr] = *opts
 
 	return nil
 }
 
 // CloseAll closes all open connections
 func (cn *Connector) CloseAll() {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
 	for _, con := range cn.connections {
 		con.Close()
 	}
@@ -0,0 +1,133 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package discovery allows discovering servers from other sources than the config file
+package discovery
+
+import (
+	"context"
+	"sync"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Discoverer is the interface a service discovery provider has to implement.
+type Discoverer interface {
+	// Run sends the complete set of discovered servers keyed by name to ch
+	// every time it changes, until ctx is done.
+	Run(ctx context.Context, ch chan<- map[string]config.Server)
+}
+
+// Manager runs discoverers and merges the servers they discovered
+type Manager struct {
+	log      *logrus.Logger
+	onUpdate func(servers map[string]config.Server)
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	generation int
+	servers    map[string]map[string]config.Server
+}
+
+// NewManager creates a new Manager.
+// onUpdate is called with the merged servers of all discoverers on every change.
+func NewManager(log *logrus.Logger, onUpdate func(servers map[string]config.Server)) *Manager {
+	return &Manager{
+		log:      log,
+		onUpdate: onUpdate,
+		servers:  map[string]map[string]config.Server{},
+	}
+}
+
+// ApplyConfig stops the running discoverers and starts the given ones keyed by provider name
+func (m *Manager) ApplyConfig(discoverers map[string]Discoverer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.generation++
+	generation := m.generation
+
+	// Servers of providers still configured are kept until they are
+	// rediscovered, the servers of removed providers are gone
+	removed := false
+	for provider := range m.servers {
+		if _, ok := discoverers[provider]; !ok {
+			delete(m.servers, provider)
+			removed = true
+		}
+	}
+	if removed {
+		m.onUpdate(m.merged())
+	}
+
+	for provider, d := range discoverers {
+		ch := make(chan map[string]config.Server)
+		go d.Run(ctx, ch)
+		go func(provider string, ch <-chan map[string]config.Server) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case servers := <-ch:
+					m.update(generation, provider, servers)
+				}
+			}
+		}(provider, ch)
+	}
+}
+
+// Stop stops all running discoverers
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.generation++
+}
+
+func (m *Manager) update(generation int, provider string, servers map[string]config.Server) {
+	// onUpdate is called with the lock held, so updates are applied in order
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Ignore updates from discoverers which have been stopped in the meantime
+	if generation != m.generation {
+		return
+	}
+	m.servers[provider] = servers
+
+	m.log.WithFields(logrus.Fields{"provider": provider}).Debugf("discovered %d servers", len(servers))
+	m.onUpdate(m.merged())
+}
+
+// merged returns the servers of all providers, m.mu must be held
+func (m *Manager) merged() map[string]config.Server {
+	merged := map[string]config.Server{}
+	for _, servers := range m.servers {
+		for name, server := range servers {
+			merged[name] = server
+		}
+	}
+	return merged
+}
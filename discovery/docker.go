@@ -0,0 +1,387 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultDockerHost default Docker API address
+	DefaultDockerHost = "unix:///var/run/docker.sock"
+	// DefaultDockerRefreshInterval default interval containers are listed in, in addition to watching events
+	DefaultDockerRefreshInterval = time.Minute
+
+	// DockerLabelPrefix prefix of the container labels used for discovery
+	DockerLabelPrefix = "srcds_exporter."
+	// DockerEnableLabel containers are only discovered when this label is `true`
+	DockerEnableLabel = DockerLabelPrefix + "enable"
+	// DockerAddressLabel address of the server, used instead of deriving it from the ports and networks
+	DockerAddressLabel = DockerLabelPrefix + "address"
+	// DockerPortLabel container port of the server (default: 27015)
+	DockerPortLabel = DockerLabelPrefix + "port"
+	// DockerModeLabel mode of the server (default: RCON)
+	DockerModeLabel = DockerLabelPrefix + "mode"
+	// DockerRCONPasswordFileLabel RCON password file of the server, read by the exporter
+	DockerRCONPasswordFileLabel = DockerLabelPrefix + "rcon_password_file"
+	// DockerLabelLabelPrefix prefix of container labels which are added as static labels to the server
+	DockerLabelLabelPrefix = DockerLabelPrefix + "label."
+
+	defaultDockerPort = 27015
+
+	// dockerRequestTimeout timeout of a container list request
+	dockerRequestTimeout = 10 * time.Second
+	// dockerEventsRetryInterval time to wait before reconnecting to the event stream
+	dockerEventsRetryInterval = 5 * time.Second
+)
+
+// DockerDiscoverer discovers servers from the labels of running Docker
+// containers. Containers are listed on container start and stop events and
+// every refresh interval.
+type DockerDiscoverer struct {
+	log    *logrus.Entry
+	cfg    config.DockerDiscovery
+	client *http.Client
+	// base URL of the API
+	base string
+
+	last map[string]config.Server
+}
+
+// NewDockerDiscoverer creates a new DockerDiscoverer
+func NewDockerDiscoverer(log *logrus.Logger, cfg config.DockerDiscovery) (*DockerDiscoverer, error) {
+	if cfg.Host == "" {
+		cfg.Host = DefaultDockerHost
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultDockerRefreshInterval
+	}
+
+	u, err := url.Parse(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{}
+	base := ""
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		}
+		// The host is ignored when dialing the socket
+		base = "http://docker"
+	case "tcp":
+		base = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker host %q", cfg.Host)
+	}
+
+	return &DockerDiscoverer{
+		log:    log.WithField("discovery", "docker"),
+		cfg:    cfg,
+		client: &http.Client{Transport: transport},
+		base:   base,
+	}, nil
+}
+
+// Run implements the Discoverer interface
+func (d *DockerDiscoverer) Run(ctx context.Context, ch chan<- map[string]config.Server) {
+	ticker := time.NewTicker(d.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	trigger := make(chan struct{}, 1)
+	go d.watchEvents(ctx, trigger)
+
+	for {
+		d.refresh(ctx, ch)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-trigger:
+		}
+	}
+}
+
+// refresh lists the containers and sends the servers if they changed. When
+// the API fails the previously discovered servers are kept.
+func (d *DockerDiscoverer) refresh(ctx context.Context, ch chan<- map[string]config.Server) {
+	servers, err := d.Discover(ctx)
+	if err != nil {
+		d.log.Errorf("failed to discover servers: %s", err)
+		return
+	}
+	if d.last != nil && reflect.DeepEqual(servers, d.last) {
+		return
+	}
+	d.last = servers
+
+	select {
+	case ch <- servers:
+	case <-ctx.Done():
+	}
+}
+
+// watchEvents triggers a refresh on container start and stop events, the
+// event stream is reconnected when it fails
+func (d *DockerDiscoverer) watchEvents(ctx context.Context, trigger chan<- struct{}) {
+	notify := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	for {
+		err := d.streamEvents(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		d.log.Warnf("docker event stream failed, reconnecting in %s: %s", dockerEventsRetryInterval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dockerEventsRetryInterval):
+		}
+	}
+}
+
+// streamEvents calls notify for each container start and stop event until the
+// stream ends
+func (d *DockerDiscoverer) streamEvents(ctx context.Context, notify func()) error {
+	filters, _ := json.Marshal(map[string][]string{
+		"type":  {"container"},
+		"event": {"start", "die", "destroy", "pause", "unpause"},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.base+"/events?filters="+url.QueryEscape(string(filters)), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	// Containers could have changed while the stream was disconnected
+	notify()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event struct {
+			Action string `json:"Action"`
+		}
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("event stream closed")
+			}
+			return err
+		}
+		d.log.Debugf("container event %q, refreshing", event.Action)
+		notify()
+	}
+}
+
+// dockerContainer container of the container list API
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Discover lists the running containers once and returns the servers keyed by address
+func (d *DockerDiscoverer) Discover(ctx context.Context) (map[string]config.Server, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerRequestTimeout)
+	defer cancel()
+
+	filters, _ := json.Marshal(map[string][]string{
+		"label": {DockerEnableLabel + "=true"},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.base+"/containers/json?filters="+url.QueryEscape(string(filters)), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d listing containers", resp.StatusCode)
+	}
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, err
+	}
+
+	servers := map[string]config.Server{}
+	for _, container := range containers {
+		server, err := d.server(container)
+		if err != nil {
+			d.log.Warnf("skipping container %s: %s", container.name(), err)
+			continue
+		}
+		servers[server.Address] = server
+	}
+
+	return servers, nil
+}
+
+// server returns the server of the container from its labels
+func (d *DockerDiscoverer) server(c dockerContainer) (config.Server, error) {
+	server := config.Server{
+		Mode:             config.QueryMode(c.Labels[DockerModeLabel]),
+		RCONPasswordFile: c.Labels[DockerRCONPasswordFileLabel],
+	}
+	if server.Mode == "" {
+		server.Mode = config.RCONMode
+	}
+
+	if server.RCONPasswordFile != "" {
+		password, err := config.ReadPasswordFile("", server.RCONPasswordFile)
+		if err != nil {
+			return server, err
+		}
+		server.RCONPassword = password
+	}
+
+	for k, v := range d.cfg.Labels {
+		if server.Labels == nil {
+			server.Labels = map[string]string{}
+		}
+		server.Labels[k] = v
+	}
+	for k, v := range c.Labels {
+		if name, ok := strings.CutPrefix(k, DockerLabelLabelPrefix); ok {
+			if server.Labels == nil {
+				server.Labels = map[string]string{}
+			}
+			server.Labels[name] = v
+		}
+	}
+
+	addr, err := d.address(c, server.Mode)
+	if err != nil {
+		return server, err
+	}
+	server.Address = addr
+
+	return server, nil
+}
+
+// address returns the address label of the container, the published host
+// port of the server port or the container IP with the server port
+func (d *DockerDiscoverer) address(c dockerContainer, mode config.QueryMode) (string, error) {
+	if addr := c.Labels[DockerAddressLabel]; addr != "" {
+		return addr, nil
+	}
+
+	port := defaultDockerPort
+	if p, ok := c.Labels[DockerPortLabel]; ok {
+		var err error
+		if port, err = strconv.Atoi(p); err != nil {
+			return "", fmt.Errorf("invalid %s label %q", DockerPortLabel, p)
+		}
+	}
+
+	// A2S and GoldSrc talk UDP, the other modes RCON over TCP. The hybrid
+	// mode uses both, of which RCON fails without a published TCP port.
+	protocol := "tcp"
+	if mode == config.A2SMode || mode == config.GoldSrcRCONMode {
+		protocol = "udp"
+	}
+	published := -1
+	for i, p := range c.Ports {
+		if p.PrivatePort != port || p.PublicPort == 0 {
+			continue
+		}
+		if published == -1 || (p.Type == protocol && c.Ports[published].Type != protocol) {
+			published = i
+		}
+	}
+	if published != -1 {
+		host := c.Ports[published].IP
+		// Published on all interfaces of the host the exporter runs on
+		if host == "" || net.ParseIP(host).IsUnspecified() {
+			host = "127.0.0.1"
+		}
+		return net.JoinHostPort(host, strconv.Itoa(c.Ports[published].PublicPort)), nil
+	}
+
+	ip := ""
+	if d.cfg.Network != "" {
+		ip = c.NetworkSettings.Networks[d.cfg.Network].IPAddress
+	} else {
+		networks := make([]string, 0, len(c.NetworkSettings.Networks))
+		for name := range c.NetworkSettings.Networks {
+			networks = append(networks, name)
+		}
+		sort.Strings(networks)
+		for _, name := range networks {
+			if ip = c.NetworkSettings.Networks[name].IPAddress; ip != "" {
+				break
+			}
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("no published port %d and no container IP", port)
+	}
+
+	return net.JoinHostPort(ip, strconv.Itoa(port)), nil
+}
+
+// name returns the name of the container or its ID
+func (c dockerContainer) name() string {
+	if len(c.Names) > 0 {
+		return strings.TrimPrefix(c.Names[0], "/")
+	}
+	return c.ID
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker Docker API stand-in serving the container list and event stream
+type fakeDocker struct {
+	mu         sync.Mutex
+	containers []string
+	events     chan string
+}
+
+// newFakeDocker serves the fake Docker API on a unix socket and returns its host
+func newFakeDocker(t *testing.T) (*fakeDocker, string) {
+	f := &fakeDocker{
+		events: make(chan string, 10),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		var filters map[string][]string
+		assert.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters))
+		assert.Equal(t, []string{"srcds_exporter.enable=true"}, filters["label"])
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		fmt.Fprintf(w, "[%s]", strings.Join(f.containers, ","))
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-f.events:
+				fmt.Fprintln(w, event)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+
+	// Unix socket paths are limited in length, t.TempDir() can be too long
+	dir, err := os.MkdirTemp("", "docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := &http.Server{Handler: mux}
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+
+	return f, "unix://" + socket
+}
+
+func (f *fakeDocker) setContainers(containers ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.containers = containers
+}
+
+func TestDockerDiscovererDiscover(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0o600))
+
+	f, host := newFakeDocker(t)
+	f.setContainers(
+		// Published port on all interfaces
+		`{"Id": "a1", "Names": ["/tf2"], "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.rcon_password_file": "`+passwordFile+`", "srcds_exporter.label.game": "tf2"},
+		  "Ports": [{"IP": "0.0.0.0", "PrivatePort": 27015, "PublicPort": 27115, "Type": "udp"}, {"IP": "0.0.0.0", "PrivatePort": 27015, "PublicPort": 27215, "Type": "tcp"}]}`,
+		// A2S prefers the published UDP port
+		`{"Id": "b2", "Names": ["/css"], "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.mode": "A2S"},
+		  "Ports": [{"IP": "10.0.0.1", "PrivatePort": 27015, "PublicPort": 27216, "Type": "tcp"}, {"IP": "10.0.0.1", "PrivatePort": 27015, "PublicPort": 27116, "Type": "udp"}]}`,
+		// Container IP of the configured network with a custom port
+		`{"Id": "c3", "Names": ["/gmod"], "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.port": "27017"},
+		  "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.3"}, "games": {"IPAddress": "172.20.0.3"}}}}`,
+		// Explicit address
+		`{"Id": "d4", "Names": ["/l4d2"], "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "l4d2.example.com:27015"}}`,
+		// No address
+		`{"Id": "e5", "Names": ["/broken"], "Labels": {"srcds_exporter.enable": "true"}}`,
+		// Missing password file
+		`{"Id": "f6", "Names": ["/nopassword"], "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "10.0.0.9:27015", "srcds_exporter.rcon_password_file": "/nonexistent"}}`,
+	)
+
+	d, err := NewDockerDiscoverer(logrus.New(), config.DockerDiscovery{
+		Host:    host,
+		Network: "games",
+		Labels:  map[string]string{"host": "node1"},
+	})
+	require.NoError(t, err)
+
+	actual, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]config.Server{
+		"127.0.0.1:27215": {
+			Address:          "127.0.0.1:27215",
+			Mode:             config.RCONMode,
+			RCONPassword:     "secret",
+			RCONPasswordFile: passwordFile,
+			Labels:           map[string]string{"host": "node1", "game": "tf2"},
+		},
+		"10.0.0.1:27116": {
+			Address: "10.0.0.1:27116",
+			Mode:    config.A2SMode,
+			Labels:  map[string]string{"host": "node1"},
+		},
+		"172.20.0.3:27017": {
+			Address: "172.20.0.3:27017",
+			Mode:    config.RCONMode,
+			Labels:  map[string]string{"host": "node1"},
+		},
+		"l4d2.example.com:27015": {
+			Address: "l4d2.example.com:27015",
+			Mode:    config.RCONMode,
+			Labels:  map[string]string{"host": "node1"},
+		},
+	}, actual)
+}
+
+func TestDockerDiscovererFollowsEvents(t *testing.T) {
+	f, host := newFakeDocker(t)
+	f.setContainers(`{"Id": "a1", "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "10.0.0.1:27015"}}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A long refresh interval, so only events can trigger a refresh
+	d, err := NewDockerDiscoverer(logrus.New(), config.DockerDiscovery{
+		Host:            host,
+		RefreshInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	ch := make(chan map[string]config.Server)
+	go d.Run(ctx, ch)
+
+	receive := func() map[string]config.Server {
+		select {
+		case servers := <-ch:
+			return servers
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for discovered servers")
+		}
+		return nil
+	}
+
+	servers := receive()
+	assert.Len(t, servers, 1)
+	assert.Contains(t, servers, "10.0.0.1:27015")
+
+	// Container started
+	f.setContainers(
+		`{"Id": "a1", "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "10.0.0.1:27015"}}`,
+		`{"Id": "b2", "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "10.0.0.2:27015"}}`,
+	)
+	f.events <- `{"Type": "container", "Action": "start", "Actor": {"ID": "b2"}}`
+	servers = receive()
+	assert.Len(t, servers, 2)
+	assert.Contains(t, servers, "10.0.0.2:27015")
+
+	// Container stopped
+	f.setContainers(`{"Id": "b2", "Labels": {"srcds_exporter.enable": "true", "srcds_exporter.address": "10.0.0.2:27015"}}`)
+	f.events <- `{"Type": "container", "Action": "die", "Actor": {"ID": "a1"}}`
+	servers = receive()
+	assert.Len(t, servers, 1)
+	assert.NotContains(t, servers, "10.0.0.1:27015")
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// TargetGroup a group of servers in server files, based on the Prometheus
+// file_sd format with the query mode and password file added
+type TargetGroup struct {
+	// Targets addresses (host:port) of the servers
+	Targets []string          `yaml:"targets" json:"targets"`
+	Labels  map[string]string `yaml:"labels" json:"labels"`
+	// Mode defaults to RCON
+	Mode config.QueryMode `yaml:"mode" json:"mode"`
+	// RCONPasswordFile relative paths are resolved against the server file's
+	// directory, if empty the default password from the options is used
+	RCONPasswordFile string `yaml:"rconPasswordFile" json:"rconPasswordFile"`
+}
+
+// FileDiscoverer discovers servers from YAML/JSON server files. The files are
+// watched for changes and re-read in the given refresh interval.
+type FileDiscoverer struct {
+	log             *logrus.Entry
+	patterns        []string
+	refreshInterval time.Duration
+
+	// last successfully read servers per file
+	files map[string]map[string]config.Server
+	last  map[string]config.Server
+}
+
+// NewFileDiscoverer creates a new FileDiscoverer for the given globs
+func NewFileDiscoverer(log *logrus.Logger, patterns []string, refreshInterval time.Duration) *FileDiscoverer {
+	return &FileDiscoverer{
+		log:             log.WithFields(logrus.Fields{"discovery": "file"}),
+		patterns:        patterns,
+		refreshInterval: refreshInterval,
+		files:           map[string]map[string]config.Server{},
+	}
+}
+
+// Run implements the Discoverer interface
+func (d *FileDiscoverer) Run(ctx context.Context, ch chan<- map[string]config.Server) {
+	var events chan fsnotify.Event
+	var errs chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		d.log.Errorf("failed to create file watcher, only refreshing every %s: %s", d.refreshInterval, err)
+	} else {
+		defer watcher.Close()
+		events = watcher.Events
+		errs = watcher.Errors
+		d.watchDirs(watcher)
+	}
+
+	ticker := time.NewTicker(d.refreshInterval)
+	defer ticker.Stop()
+
+	d.refresh(ctx, ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if !d.matches(event.Name) {
+				continue
+			}
+			d.log.Debugf("server file %s changed (%s)", event.Name, event.Op)
+			d.refresh(ctx, ch)
+		case err := <-errs:
+			d.log.Errorf("file watcher error: %s", err)
+		case <-ticker.C:
+			if watcher != nil {
+				// Directories might have been created in the meantime
+				d.watchDirs(watcher)
+			}
+			d.refresh(ctx, ch)
+		}
+	}
+}
+
+// watchDirs watches the directories of the globs, so created, renamed and
+// removed files are noticed as well
+func (d *FileDiscoverer) watchDirs(watcher *fsnotify.Watcher) {
+	for _, pattern := range d.patterns {
+		dir := filepath.Dir(pattern)
+		if err := watcher.Add(dir); err != nil {
+			d.log.Errorf("failed to watch directory %s: %s", dir, err)
+		}
+	}
+}
+
+func (d *FileDiscoverer) matches(file string) bool {
+	for _, pattern := range d.patterns {
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh reads all server files and sends the servers if they changed.
+// Files that fail to be read keep their previously read servers.
+func (d *FileDiscoverer) refresh(ctx context.Context, ch chan<- map[string]config.Server) {
+	found := map[string]struct{}{}
+	for _, pattern := range d.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			d.log.Errorf("invalid server files glob %q: %s", pattern, err)
+			continue
+		}
+		for _, file := range matches {
+			found[file] = struct{}{}
+			servers, err := ReadServerFile(file)
+			if err != nil {
+				d.log.Errorf("failed to read server file %s: %s", file, err)
+				continue
+			}
+			d.files[file] = servers
+		}
+	}
+	for file := range d.files {
+		if _, ok := found[file]; !ok {
+			delete(d.files, file)
+		}
+	}
+
+	servers := map[string]config.Server{}
+	for _, fileServers := range d.files {
+		for name, server := range fileServers {
+			servers[name] = server
+		}
+	}
+	if d.last != nil && reflect.DeepEqual(servers, d.last) {
+		return
+	}
+	d.last = servers
+
+	select {
+	case ch <- servers:
+	case <-ctx.Done():
+	}
+}
+
+// ReadServerFile reads the target groups from a YAML or JSON (`.json`
+// extension) server file, the servers are keyed by their address.
+func ReadServerFile(file string) (map[string]config.Server, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []TargetGroup
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&groups)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(&groups)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return serversFromGroups(groups, filepath.Dir(file))
+}
+
+// serversFromGroups returns the servers of the target groups keyed by their
+// address, relative password file paths are resolved against dir
+func serversFromGroups(groups []TargetGroup, dir string) (map[string]config.Server, error) {
+	servers := map[string]config.Server{}
+	for i, group := range groups {
+		mode := group.Mode
+		if mode == "" {
+			mode = config.RCONMode
+		}
+
+		var password config.Secret
+		if group.RCONPasswordFile != "" {
+			var err error
+			password, err = config.ReadPasswordFile(dir, group.RCONPasswordFile)
+			if err != nil {
+				return nil, fmt.Errorf("group %d: %w", i, err)
+			}
+		}
+
+		for _, target := range group.Targets {
+			servers[target] = config.Server{
+				Address:          target,
+				Mode:             mode,
+				RCONPassword:     password,
+				RCONPasswordFile: group.RCONPasswordFile,
+				Labels:           group.Labels,
+			}
+		}
+	}
+
+	return servers, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var readServerFileTests = []struct {
+	name     string
+	file     string
+	content  string
+	expected map[string]config.Server
+	errOkay  bool
+}{
+	{
+		name: "yaml",
+		file: "servers.yml",
+		content: `- targets:
+    - 127.0.0.1:27015
+    - 127.0.0.1:27016
+  labels:
+    region: eu
+  rconPasswordFile: password
+- targets:
+    - 127.0.0.1:27017
+  mode: A2S
+`,
+		expected: map[string]config.Server{
+			"127.0.0.1:27015": {
+				Address:          "127.0.0.1:27015",
+				Mode:             config.RCONMode,
+				RCONPassword:     "secret",
+				RCONPasswordFile: "password",
+				Labels:           map[string]string{"region": "eu"},
+			},
+			"127.0.0.1:27016": {
+				Address:          "127.0.0.1:27016",
+				Mode:             config.RCONMode,
+				RCONPassword:     "secret",
+				RCONPasswordFile: "password",
+				Labels:           map[string]string{"region": "eu"},
+			},
+			"127.0.0.1:27017": {
+				Address: "127.0.0.1:27017",
+				Mode:    config.A2SMode,
+			},
+		},
+	},
+	{
+		name:    "json",
+		file:    "servers.json",
+		content: `[{"targets": ["127.0.0.1:27015"], "labels": {"game": "tf2"}, "mode": "A2S"}]`,
+		expected: map[string]config.Server{
+			"127.0.0.1:27015": {
+				Address: "127.0.0.1:27015",
+				Mode:    config.A2SMode,
+				Labels:  map[string]string{"game": "tf2"},
+			},
+		},
+	},
+	{
+		name:    "unknown key",
+		file:    "servers.json",
+		content: `[{"targets": ["127.0.0.1:27015"], "rconPassword": "plain"}]`,
+		errOkay: true,
+	},
+	{
+		name: "missing password file",
+		file: "servers.yml",
+		content: `- targets: ["127.0.0.1:27015"]
+  rconPasswordFile: missing
+`,
+		errOkay: true,
+	},
+}
+
+func TestReadServerFile(t *testing.T) {
+	for _, tt := range readServerFileTests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("secret\n"), 0o600))
+			file := filepath.Join(dir, tt.file)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0o600))
+
+			actual, err := ReadServerFile(file)
+			if tt.errOkay {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestFileDiscovererWatchesFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "servers.yml")
+	require.NoError(t, os.WriteFile(file, []byte(`- targets: ["127.0.0.1:27015"]
+  mode: A2S
+`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A long refresh interval, so only the file watcher can notice changes
+	d := NewFileDiscoverer(logrus.New(), []string{filepath.Join(dir, "*.yml")}, time.Hour)
+	ch := make(chan map[string]config.Server)
+	go d.Run(ctx, ch)
+
+	receive := func() map[string]config.Server {
+		select {
+		case servers := <-ch:
+			return servers
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for discovered servers")
+		}
+		return nil
+	}
+
+	servers := receive()
+	assert.Contains(t, servers, "127.0.0.1:27015")
+
+	// New file matching the glob
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "more.yml"), []byte(`- targets: ["127.0.0.1:27016"]
+  mode: A2S
+`), 0o600))
+	servers = receive()
+	assert.Len(t, servers, 2)
+	assert.Contains(t, servers, "127.0.0.1:27016")
+
+	// Removed file
+	require.NoError(t, os.Remove(file))
+	servers = receive()
+	assert.Len(t, servers, 1)
+	assert.NotContains(t, servers, "127.0.0.1:27015")
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHTTPRefreshInterval default interval the HTTP endpoint is polled in
+	DefaultHTTPRefreshInterval = time.Minute
+	// DefaultHTTPTimeout default timeout of a HTTP request
+	DefaultHTTPTimeout = 10 * time.Second
+
+	// maxPterodactylPages upper limit of pages requested, in case the panel keeps returning a next page
+	maxPterodactylPages = 100
+)
+
+// HTTPDiscoverer discovers servers by periodically polling a HTTP endpoint,
+// either a Pterodactyl panel API or an endpoint returning target groups.
+type HTTPDiscoverer struct {
+	log    *logrus.Entry
+	cfg    config.HTTPDiscovery
+	client *http.Client
+
+	last map[string]config.Server
+}
+
+// NewHTTPDiscoverer creates a new HTTPDiscoverer
+func NewHTTPDiscoverer(log *logrus.Logger, cfg config.HTTPDiscovery) *HTTPDiscoverer {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultHTTPRefreshInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHTTPTimeout
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = config.RCONMode
+	}
+
+	return &HTTPDiscoverer{
+		log: log.WithFields(logrus.Fields{"discovery": "http", "provider": cfg.Name}),
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// Run implements the Discoverer interface
+func (d *HTTPDiscoverer) Run(ctx context.Context, ch chan<- map[string]config.Server) {
+	ticker := time.NewTicker(d.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		d.refresh(ctx, ch)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh polls the endpoint and sends the servers if they changed. When the
+// endpoint fails the previously discovered servers are kept.
+func (d *HTTPDiscoverer) refresh(ctx context.Context, ch chan<- map[string]config.Server) {
+	servers, err := d.Discover(ctx)
+	if err != nil {
+		d.log.Errorf("failed to discover servers: %s", err)
+		return
+	}
+	if d.last != nil && reflect.DeepEqual(servers, d.last) {
+		return
+	}
+	d.last = servers
+
+	select {
+	case ch <- servers:
+	case <-ctx.Done():
+	}
+}
+
+// Discover polls the endpoint once and returns the servers keyed by address
+func (d *HTTPDiscoverer) Discover(ctx context.Context) (map[string]config.Server, error) {
+	switch d.cfg.Format {
+	case config.PterodactylFormat:
+		return d.discoverPterodactyl(ctx)
+	case config.GenericFormat:
+		return d.discoverGeneric(ctx)
+	default:
+		return nil, fmt.Errorf("unknown format %q", d.cfg.Format)
+	}
+}
+
+func (d *HTTPDiscoverer) discoverGeneric(ctx context.Context) (map[string]config.Server, error) {
+	var groups []TargetGroup
+	if err := d.get(ctx, d.cfg.URL, &groups); err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if groups[i].Mode == "" {
+			groups[i].Mode = d.cfg.Mode
+		}
+		// The response must not pick local files to read, the password would
+		// be sent to the addresses it returns
+		if groups[i].RCONPasswordFile != "" {
+			d.log.Warnf("group %d: ignoring rconPasswordFile of the response, only the provider's password file is used", i)
+		}
+		groups[i].RCONPasswordFile = d.cfg.RCONPasswordFile
+		groups[i].Labels = mergeLabels(d.cfg.Labels, groups[i].Labels)
+	}
+
+	return serversFromGroups(groups, "")
+}
+
+// pterodactylList server list response of the Pterodactyl client
+// (`/api/client`) and application (`/api/application/servers`) API
+type pterodactylList struct {
+	Data []struct {
+		Attributes pterodactylServer `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Pagination struct {
+			CurrentPage int `json:"current_page"`
+			TotalPages  int `json:"total_pages"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+type pterodactylServer struct {
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+	// Suspended is set by the application API
+	Suspended bool `json:"suspended"`
+	// Status is set by the client API, e.g., "suspended"
+	Status *string `json:"status"`
+	// Allocation ID of the default allocation, set by the application API
+	Allocation    int `json:"allocation"`
+	Relationships struct {
+		Allocations struct {
+			Data []struct {
+				Attributes pterodactylAllocation `json:"attributes"`
+			} `json:"data"`
+		} `json:"allocations"`
+	} `json:"relationships"`
+}
+
+type pterodactylAllocation struct {
+	ID   int    `json:"id"`
+	IP   string `json:"ip"`
+	Port int    `json:"port"`
+	// IPAlias set by the client API
+	IPAlias *string `json:"ip_alias"`
+	// Alias set by the application API
+	Alias *string `json:"alias"`
+	// IsDefault set by the client API
+	IsDefault bool `json:"is_default"`
+}
+
+func (d *HTTPDiscoverer) discoverPterodactyl(ctx context.Context) (map[string]config.Server, error) {
+	u, err := url.Parse(d.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	// The application API only returns allocations when requested
+	if query.Get("include") == "" {
+		query.Set("include", "allocations")
+	}
+
+	password := config.Secret("")
+	if d.cfg.RCONPasswordFile != "" {
+		if password, err = config.ReadPasswordFile("", d.cfg.RCONPasswordFile); err != nil {
+			return nil, err
+		}
+	}
+
+	servers := map[string]config.Server{}
+	for page := 1; page <= maxPterodactylPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+		u.RawQuery = query.Encode()
+
+		var list pterodactylList
+		if err := d.get(ctx, u.String(), &list); err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Data {
+			server := item.Attributes
+			if server.Suspended || (server.Status != nil && *server.Status == "suspended") {
+				continue
+			}
+			addr, ok := server.address()
+			if !ok {
+				d.log.Warnf("server %q (%s) has no usable allocation, skipping", server.Name, server.Identifier)
+				continue
+			}
+			servers[addr] = config.Server{
+				Address:          addr,
+				Mode:             d.cfg.Mode,
+				RCONPassword:     password,
+				RCONPasswordFile: d.cfg.RCONPasswordFile,
+				Labels:           d.cfg.Labels,
+			}
+		}
+
+		if list.Meta.Pagination.CurrentPage >= list.Meta.Pagination.TotalPages {
+			break
+		}
+	}
+
+	return servers, nil
+}
+
+// address returns the address of the default allocation of the server
+func (s pterodactylServer) address() (string, bool) {
+	var allocation *pterodactylAllocation
+	for i, a := range s.Relationships.Allocations.Data {
+		if a.Attributes.IsDefault || (s.Allocation != 0 && a.Attributes.ID == s.Allocation) {
+			allocation = &s.Relationships.Allocations.Data[i].Attributes
+			break
+		}
+	}
+	if allocation == nil {
+		if len(s.Relationships.Allocations.Data) == 0 {
+			return "", false
+		}
+		allocation = &s.Relationships.Allocations.Data[0].Attributes
+	}
+
+	host := allocation.IP
+	for _, alias := range []*string{allocation.IPAlias, allocation.Alias} {
+		if alias != nil && *alias != "" {
+			host = *alias
+			break
+		}
+	}
+	if host == "" || allocation.Port == 0 {
+		return "", false
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(allocation.Port)), true
+}
+
+// get requests the URL and decodes the JSON response into out
+func (d *HTTPDiscoverer) get(ctx context.Context, u string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if d.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+string(d.cfg.APIKey))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Redacted())
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// mergeLabels returns the labels of a and b, labels of b take precedence
+func mergeLabels(a, b map[string]string) map[string]string {
+	if len(a) == 0 {
+		return b
+	}
+	out := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,382 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Pages of a Pterodactyl application API server list
+var pterodactylApplicationPages = []string{
+	`{
+  "object": "list",
+  "data": [
+    {
+      "object": "server",
+      "attributes": {
+        "id": 1,
+        "identifier": "1a7ce997",
+        "name": "TF2 Casual",
+        "suspended": false,
+        "allocation": 12,
+        "relationships": {
+          "allocations": {
+            "object": "list",
+            "data": [
+              {"object": "allocation", "attributes": {"id": 11, "ip": "10.0.0.5", "alias": null, "port": 27020, "assigned": true}},
+              {"object": "allocation", "attributes": {"id": 12, "ip": "10.0.0.5", "alias": "tf2.example.com", "port": 27015, "assigned": true}}
+            ]
+          }
+        }
+      }
+    },
+    {
+      "object": "server",
+      "attributes": {
+        "id": 2,
+        "identifier": "5b2dd123",
+        "name": "Suspended",
+        "suspended": true,
+        "allocation": 13,
+        "relationships": {
+          "allocations": {
+            "object": "list",
+            "data": [
+              {"object": "allocation", "attributes": {"id": 13, "ip": "10.0.0.6", "alias": null, "port": 27015, "assigned": true}}
+            ]
+          }
+        }
+      }
+    }
+  ],
+  "meta": {"pagination": {"total": 3, "count": 2, "per_page": 2, "current_page": 1, "total_pages": 2}}
+}`,
+	`{
+  "object": "list",
+  "data": [
+    {
+      "object": "server",
+      "attributes": {
+        "id": 3,
+        "identifier": "9f0e11aa",
+        "name": "CS2 Competitive",
+        "suspended": false,
+        "allocation": 14,
+        "relationships": {
+          "allocations": {
+            "object": "list",
+            "data": [
+              {"object": "allocation", "attributes": {"id": 14, "ip": "10.0.0.7", "alias": null, "port": 27015, "assigned": true}}
+            ]
+          }
+        }
+      }
+    }
+  ],
+  "meta": {"pagination": {"total": 3, "count": 1, "per_page": 2, "current_page": 2, "total_pages": 2}}
+}`,
+}
+
+// Pterodactyl client API server list
+const pterodactylClientList = `{
+  "object": "list",
+  "data": [
+    {
+      "object": "server",
+      "attributes": {
+        "identifier": "1a7ce997",
+        "name": "TF2 Casual",
+        "status": null,
+        "relationships": {
+          "allocations": {
+            "object": "list",
+            "data": [
+              {"object": "allocation", "attributes": {"id": 11, "ip": "10.0.0.5", "ip_alias": null, "port": 27020, "is_default": false}},
+              {"object": "allocation", "attributes": {"id": 12, "ip": "10.0.0.5", "ip_alias": null, "port": 27015, "is_default": true}}
+            ]
+          }
+        }
+      }
+    },
+    {
+      "object": "server",
+      "attributes": {
+        "identifier": "5b2dd123",
+        "name": "Suspended",
+        "status": "suspended",
+        "relationships": {
+          "allocations": {
+            "object": "list",
+            "data": [
+              {"object": "allocation", "attributes": {"id": 13, "ip": "10.0.0.6", "ip_alias": null, "port": 27015, "is_default": true}}
+            ]
+          }
+        }
+      }
+    }
+  ],
+  "meta": {"pagination": {"total": 2, "count": 2, "per_page": 50, "current_page": 1, "total_pages": 1}}
+}`
+
+// newPanel returns a panel stand-in serving the application API, the client
+// API and a generic target group list, requests need the API key
+func newPanel(t *testing.T, generic func() string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/application/servers", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "allocations", r.URL.Query().Get("include"))
+		var page int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		if page < 1 || page > len(pterodactylApplicationPages) {
+			http.Error(w, "invalid page", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, pterodactylApplicationPages[page-1])
+	})
+	mux.HandleFunc("/api/client", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, pterodactylClientList)
+	})
+	mux.HandleFunc("/targets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, generic())
+	})
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ptla_secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHTTPDiscovererDiscover(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0o600))
+
+	panel := newPanel(t, func() string {
+		return `[
+  {"targets": ["10.0.0.8:27015"], "labels": {"game": "gmod"}},
+  {"targets": ["10.0.0.9:27015"], "mode": "A2S", "labels": {"region": "us"}}
+]`
+	})
+
+	tests := []struct {
+		name     string
+		cfg      config.HTTPDiscovery
+		expected map[string]config.Server
+		errOkay  bool
+	}{
+		{
+			name: "pterodactyl application API",
+			cfg: config.HTTPDiscovery{
+				URL:              panel.URL + "/api/application/servers",
+				Format:           config.PterodactylFormat,
+				APIKey:           "ptla_secret",
+				RCONPasswordFile: passwordFile,
+				Labels:           map[string]string{"region": "eu"},
+			},
+			expected: map[string]config.Server{
+				"tf2.example.com:27015": {
+					Address:          "tf2.example.com:27015",
+					Mode:             config.RCONMode,
+					RCONPassword:     "secret",
+					RCONPasswordFile: passwordFile,
+					Labels:           map[string]string{"region": "eu"},
+				},
+				"10.0.0.7:27015": {
+					Address:          "10.0.0.7:27015",
+					Mode:             config.RCONMode,
+					RCONPassword:     "secret",
+					RCONPasswordFile: passwordFile,
+					Labels:           map[string]string{"region": "eu"},
+				},
+			},
+		},
+		{
+			name: "pterodactyl client API",
+			cfg: config.HTTPDiscovery{
+				URL:    panel.URL + "/api/client",
+				Format: config.PterodactylFormat,
+				APIKey: "ptla_secret",
+				Mode:   config.A2SMode,
+			},
+			expected: map[string]config.Server{
+				"10.0.0.5:27015": {
+					Address: "10.0.0.5:27015",
+					Mode:    config.A2SMode,
+				},
+			},
+		},
+		{
+			name: "generic",
+			cfg: config.HTTPDiscovery{
+				URL:    panel.URL + "/targets",
+				Format: config.GenericFormat,
+				APIKey: "ptla_secret",
+				Labels: map[string]string{"region": "eu"},
+			},
+			expected: map[string]config.Server{
+				"10.0.0.8:27015": {
+					Address: "10.0.0.8:27015",
+					Mode:    config.RCONMode,
+					Labels:  map[string]string{"region": "eu", "game": "gmod"},
+				},
+				"10.0.0.9:27015": {
+					Address: "10.0.0.9:27015",
+					Mode:    config.A2SMode,
+					Labels:  map[string]string{"region": "us"},
+				},
+			},
+		},
+		{
+			name: "wrong API key",
+			cfg: config.HTTPDiscovery{
+				URL:    panel.URL + "/api/client",
+				Format: config.PterodactylFormat,
+				APIKey: "wrong",
+			},
+			errOkay: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewHTTPDiscoverer(logrus.New(), tt.cfg)
+			actual, err := d.Discover(context.Background())
+			if tt.errOkay {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestHTTPDiscovererGenericPasswordFile(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0o600))
+	// A file the response points at, it must not be read
+	leaked := filepath.Join(dir, "leaked")
+	require.NoError(t, os.WriteFile(leaked, []byte("leaked\n"), 0o600))
+
+	panel := newPanel(t, func() string {
+		return `[
+  {"targets": ["10.0.0.8:27015"], "rconPasswordFile": "/etc/passwd"},
+  {"targets": ["10.0.0.9:27015"], "rconPasswordFile": "` + leaked + `"},
+  {"targets": ["10.0.0.10:27015"], "rconPasswordFile": "/nonexistent/password"}
+]`
+	})
+
+	tests := []struct {
+		name         string
+		passwordFile string
+		password     config.Secret
+	}{
+		{name: "provider password file", passwordFile: passwordFile, password: "secret"},
+		{name: "no provider password file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewHTTPDiscoverer(logrus.New(), config.HTTPDiscovery{
+				URL:              panel.URL + "/targets",
+				Format:           config.GenericFormat,
+				APIKey:           "ptla_secret",
+				RCONPasswordFile: tt.passwordFile,
+			})
+			servers, err := d.Discover(context.Background())
+			require.NoError(t, err)
+			require.Len(t, servers, 3)
+			for addr, server := range servers {
+				assert.Equal(t, tt.password, server.RCONPassword, addr)
+				assert.Equal(t, tt.passwordFile, server.RCONPasswordFile, addr)
+			}
+		})
+	}
+}
+
+func TestHTTPDiscovererRun(t *testing.T) {
+	var mu sync.Mutex
+	response := `[{"targets": ["10.0.0.8:27015"]}]`
+	panel := newPanel(t, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return response
+	})
+	setResponse := func(r string) {
+		mu.Lock()
+		defer mu.Unlock()
+		response = r
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := NewHTTPDiscoverer(logrus.New(), config.HTTPDiscovery{
+		Name:            "test",
+		URL:             panel.URL + "/targets",
+		Format:          config.GenericFormat,
+		APIKey:          "ptla_secret",
+		RefreshInterval: 20 * time.Millisecond,
+	})
+	ch := make(chan map[string]config.Server)
+	go d.Run(ctx, ch)
+
+	receive := func() map[string]config.Server {
+		select {
+		case servers := <-ch:
+			return servers
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for discovered servers")
+		}
+		return nil
+	}
+
+	servers := receive()
+	assert.Len(t, servers, 1)
+	assert.Contains(t, servers, "10.0.0.8:27015")
+
+	// Server added
+	setResponse(`[{"targets": ["10.0.0.8:27015", "10.0.0.9:27015"]}]`)
+	servers = receive()
+	assert.Len(t, servers, 2)
+
+	// Invalid responses keep the last discovered servers, the next change is sent
+	setResponse(`not json`)
+	time.Sleep(100 * time.Millisecond)
+	setResponse(`[{"targets": ["10.0.0.9:27015"]}]`)
+	servers = receive()
+	assert.Len(t, servers, 1)
+	assert.Contains(t, servers, "10.0.0.9:27015")
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMasterServer default Steam master server address
+	DefaultMasterServer = "hl2master.steampowered.com:27011"
+	// DefaultSteamWebAPIURL default Steam Web API base URL
+	DefaultSteamWebAPIURL = "https://api.steampowered.com"
+	// DefaultSteamRefreshInterval default interval the server list is requested in
+	DefaultSteamRefreshInterval = 5 * time.Minute
+	// DefaultSteamTimeout default timeout of a server list request
+	DefaultSteamTimeout = 10 * time.Second
+
+	// MasterServerRegionAll master server region code for all regions
+	MasterServerRegionAll byte = 0xFF
+
+	// masterServerMaxPages upper limit of requests for one server list
+	masterServerMaxPages = 100
+	// steamWebAPILimit maximum number of servers requested from the Web API
+	steamWebAPILimit = 5000
+)
+
+var (
+	masterServerResponseHeader = []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+	// masterServerSeed first and last address of a server list
+	masterServerSeed = "0.0.0.0:0"
+)
+
+// SteamDiscoverer discovers servers by periodically requesting a filtered
+// server list from the Steam master server or Web API. The servers are
+// added in A2S mode.
+type SteamDiscoverer struct {
+	log    *logrus.Entry
+	cfg    config.SteamDiscovery
+	client *http.Client
+
+	last map[string]config.Server
+}
+
+// NewSteamDiscoverer creates a new SteamDiscoverer
+func NewSteamDiscoverer(log *logrus.Logger, cfg config.SteamDiscovery) *SteamDiscoverer {
+	if cfg.Source == "" {
+		cfg.Source = config.MasterServerSource
+	}
+	if cfg.MasterServer == "" {
+		cfg.MasterServer = DefaultMasterServer
+	}
+	if cfg.WebAPIURL == "" {
+		cfg.WebAPIURL = DefaultSteamWebAPIURL
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultSteamRefreshInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultSteamTimeout
+	}
+
+	return &SteamDiscoverer{
+		log: log.WithFields(logrus.Fields{"discovery": "steam", "provider": cfg.Name}),
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// Run implements the Discoverer interface
+func (d *SteamDiscoverer) Run(ctx context.Context, ch chan<- map[string]config.Server) {
+	ticker := time.NewTicker(d.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		d.refresh(ctx, ch)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh requests the server list and sends the servers if they changed.
+// When the request fails the previously discovered servers are kept.
+func (d *SteamDiscoverer) refresh(ctx context.Context, ch chan<- map[string]config.Server) {
+	servers, err := d.Discover(ctx)
+	if err != nil {
+		d.log.Errorf("failed to discover servers: %s", err)
+		return
+	}
+	if d.last != nil && reflect.DeepEqual(servers, d.last) {
+		return
+	}
+	d.last = servers
+
+	select {
+	case ch <- servers:
+	case <-ctx.Done():
+	}
+}
+
+// Discover requests the server list once and returns the servers keyed by address
+func (d *SteamDiscoverer) Discover(ctx context.Context) (map[string]config.Server, error) {
+	var addrs []string
+	var err error
+	switch d.cfg.Source {
+	case config.MasterServerSource:
+		ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+		defer cancel()
+		addrs, err = QueryMasterServer(ctx, d.cfg.MasterServer, MasterServerRegionAll, d.cfg.Filter)
+	case config.WebAPISource:
+		addrs, err = d.queryWebAPI(ctx)
+	default:
+		err = fmt.Errorf("unknown source %q", d.cfg.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make(map[string]config.Server, len(addrs))
+	for _, addr := range addrs {
+		servers[addr] = config.Server{
+			Address: addr,
+			Mode:    config.A2SMode,
+			Labels:  d.cfg.Labels,
+		}
+	}
+	return servers, nil
+}
+
+// queryWebAPI requests the server list from `IGameServersService/GetServerList`
+func (d *SteamDiscoverer) queryWebAPI(ctx context.Context) ([]string, error) {
+	query := url.Values{}
+	query.Set("key", string(d.cfg.APIKey))
+	query.Set("filter", d.cfg.Filter)
+	query.Set("limit", strconv.Itoa(steamWebAPILimit))
+	u := strings.TrimSuffix(d.cfg.WebAPIURL, "/") + "/IGameServersService/GetServerList/v1/?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// The error contains the URL with the API key
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("failed to request server list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d from steam web api", resp.StatusCode)
+	}
+
+	var list struct {
+		Response struct {
+			Servers []struct {
+				// Addr address with the query port
+				Addr string `json:"addr"`
+			} `json:"servers"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(list.Response.Servers))
+	for _, server := range list.Response.Servers {
+		addrs = append(addrs, server.Addr)
+	}
+	return addrs, nil
+}
+
+// QueryMasterServer requests the addresses of all servers matching the filter
+// from a master server using the Steam master server query protocol. The list
+// is requested in pages until the master server sends the end marker.
+func QueryMasterServer(ctx context.Context, addr string, region byte, filter string) ([]string, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var addrs []string
+	seen := map[string]struct{}{}
+	seed := masterServerSeed
+	buf := make([]byte, 4096)
+	for page := 0; page < masterServerMaxPages; page++ {
+		req := []byte{0x31, region}
+		req = append(req, seed...)
+		req = append(req, 0)
+		req = append(req, filter...)
+		req = append(req, 0)
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master server response: %w", err)
+		}
+		found, done, err := parseMasterServerResponse(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range found {
+			if _, ok := seen[a]; ok {
+				continue
+			}
+			seen[a] = struct{}{}
+			addrs = append(addrs, a)
+		}
+		if done {
+			return addrs, nil
+		}
+		if len(found) == 0 {
+			return nil, errors.New("master server response without servers and end marker")
+		}
+		seed = found[len(found)-1]
+	}
+
+	return nil, fmt.Errorf("master server sent more than %d pages", masterServerMaxPages)
+}
+
+// parseMasterServerResponse returns the addresses of a master server response
+// and if it contains the end marker
+func parseMasterServerResponse(data []byte) ([]string, bool, error) {
+	if !bytes.HasPrefix(data, masterServerResponseHeader) {
+		return nil, false, errors.New("invalid master server response header")
+	}
+	data = data[len(masterServerResponseHeader):]
+	if len(data)%6 != 0 {
+		return nil, false, fmt.Errorf("invalid master server response length %d", len(data))
+	}
+
+	var addrs []string
+	for i := 0; i < len(data); i += 6 {
+		ip := net.IP(data[i : i+4])
+		port := binary.BigEndian.Uint16(data[i+4 : i+6])
+		addr := net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+		if addr == masterServerSeed {
+			return addrs, true, nil
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, false, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMasterServer serves the servers matching the filter over the master
+// server query protocol, pageSize addresses per response, and returns its address
+func startMasterServer(t *testing.T, filter string, servers []string, pageSize int) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			if len(req) < 2 || req[0] != 0x31 || req[1] != MasterServerRegionAll {
+				continue
+			}
+			parts := bytes.Split(req[2:], []byte{0})
+			if len(parts) < 2 || string(parts[1]) != filter {
+				continue
+			}
+			seed := string(parts[0])
+
+			// Continue after the seed address
+			start := 0
+			for i, s := range servers {
+				if s == seed {
+					start = i + 1
+				}
+			}
+			end := start + pageSize
+			page := []string{}
+			if end >= len(servers) {
+				page = append(page, servers[start:]...)
+				page = append(page, "0.0.0.0:0")
+			} else {
+				page = append(page, servers[start:end]...)
+			}
+
+			resp := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+			for _, s := range page {
+				host, port, _ := net.SplitHostPort(s)
+				p, _ := strconv.Atoi(port)
+				resp = append(resp, net.ParseIP(host).To4()...)
+				resp = binary.BigEndian.AppendUint16(resp, uint16(p))
+			}
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestQueryMasterServer(t *testing.T) {
+	servers := []string{}
+	for i := 0; i < 5; i++ {
+		servers = append(servers, fmt.Sprintf("1.2.3.4:%d", 27015+i))
+	}
+	addr := startMasterServer(t, `\gameaddr\1.2.3.4`, servers, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	actual, err := QueryMasterServer(ctx, addr, MasterServerRegionAll, `\gameaddr\1.2.3.4`)
+	require.NoError(t, err)
+	assert.Equal(t, servers, actual)
+
+	// No response for another filter
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = QueryMasterServer(ctx, addr, MasterServerRegionAll, `\gameaddr\5.6.7.8`)
+	assert.Error(t, err)
+}
+
+func TestParseMasterServerResponse(t *testing.T) {
+	header := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x66, 0x0A}
+
+	addrs, done, err := parseMasterServerResponse(append(header, 1, 2, 3, 4, 0x69, 0x87, 0, 0, 0, 0, 0, 0))
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, []string{"1.2.3.4:27015"}, addrs)
+
+	_, _, err = parseMasterServerResponse([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x49})
+	assert.Error(t, err)
+
+	_, _, err = parseMasterServerResponse(append(header, 1, 2, 3))
+	assert.Error(t, err)
+}
+
+func TestSteamDiscovererDiscover(t *testing.T) {
+	masterServer := startMasterServer(t, `\gameaddr\1.2.3.4`, []string{"1.2.3.4:27015", "1.2.3.4:27016"}, 10)
+
+	webAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/IGameServersService/GetServerList/v1/", r.URL.Path)
+		if r.URL.Query().Get("key") != "steam_key" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		assert.Equal(t, `\gameaddr\5.6.7.8`, r.URL.Query().Get("filter"))
+		fmt.Fprint(w, `{"response": {"servers": [{"addr": "5.6.7.8:27016", "gameport": 27015, "name": "Server 1"}]}}`)
+	}))
+	defer webAPI.Close()
+
+	tests := []struct {
+		name     string
+		cfg      config.SteamDiscovery
+		expected map[string]config.Server
+		errOkay  bool
+	}{
+		{
+			name: "master server",
+			cfg: config.SteamDiscovery{
+				Filter:       `\gameaddr\1.2.3.4`,
+				MasterServer: masterServer,
+				Labels:       map[string]string{"network": "example"},
+			},
+			expected: map[string]config.Server{
+				"1.2.3.4:27015": {Address: "1.2.3.4:27015", Mode: config.A2SMode, Labels: map[string]string{"network": "example"}},
+				"1.2.3.4:27016": {Address: "1.2.3.4:27016", Mode: config.A2SMode, Labels: map[string]string{"network": "example"}},
+			},
+		},
+		{
+			name: "web api",
+			cfg: config.SteamDiscovery{
+				Source:    config.WebAPISource,
+				Filter:    `\gameaddr\5.6.7.8`,
+				WebAPIURL: webAPI.URL,
+				APIKey:    "steam_key",
+			},
+			expected: map[string]config.Server{
+				"5.6.7.8:27016": {Address: "5.6.7.8:27016", Mode: config.A2SMode},
+			},
+		},
+		{
+			name: "web api wrong key",
+			cfg: config.SteamDiscovery{
+				Source:    config.WebAPISource,
+				Filter:    `\gameaddr\5.6.7.8`,
+				WebAPIURL: webAPI.URL,
+				APIKey:    "wrong",
+			},
+			errOkay: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewSteamDiscoverer(logrus.New(), tt.cfg)
+			actual, err := d.Discover(context.Background())
+			if tt.errOkay {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
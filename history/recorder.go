@@ -0,0 +1,295 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package history records the sessions of players on the servers in the state
+// store, so it can be looked up when and where a player was online.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/galexrt/srcds_exporter/state"
+)
+
+const (
+	// DefaultRetention time sessions are kept after they ended when no
+	// retention is given
+	DefaultRetention = 30 * 24 * time.Hour
+
+	// sessionsBucket sessions keyed by server, start and SteamID
+	sessionsBucket = "sessions"
+	// playerSessionsBucket sessions keyed by SteamID, start and server
+	playerSessionsBucket = "playerSessions"
+
+	// pruneInterval interval in which sessions older than the retention are
+	// deleted
+	pruneInterval = time.Hour
+)
+
+// SessionMaxGap time between two observations of a player up to which the
+// player is considered online in between, longer gaps (exporter restarts,
+// unreachable servers) end the session at the last observation
+var SessionMaxGap = 10 * time.Minute
+
+// Session time a player was online on a server
+type Session struct {
+	Server  string    `json:"server"`
+	SteamID string    `json:"steamID,omitempty"`
+	Name    string    `json:"name"`
+	Start   time.Time `json:"start"`
+	// End time the player was last seen in the session
+	End time.Time `json:"end"`
+	// Online whether the session is ongoing
+	Online bool `json:"online"`
+}
+
+// Duration returns the length of the session
+func (s *Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Filter selects sessions, empty fields match all sessions
+type Filter struct {
+	SteamID string
+	Server  string
+	// From sessions which ended before From are skipped
+	From time.Time
+	// To sessions which started after To are skipped
+	To time.Time
+	// Limit maximum count of sessions returned, 0 returns all
+	Limit int
+}
+
+// matches returns whether the session matches the filter
+func (f Filter) matches(s *Session) bool {
+	if f.SteamID != "" && s.SteamID != f.SteamID {
+		return false
+	}
+	if f.Server != "" && s.Server != f.Server {
+		return false
+	}
+	if !f.From.IsZero() && s.End.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && s.Start.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Recorder records player sessions from the players seen on the servers
+type Recorder struct {
+	store     *state.Store
+	retention time.Duration
+
+	mu sync.Mutex
+	// open ongoing sessions per server and SteamID
+	open      map[string]map[string]*Session
+	lastPrune time.Time
+}
+
+// NewRecorder creates a recorder keeping sessions in store for retention after
+// they ended, sessions which were ongoing when the recorder was last used are
+// continued
+func NewRecorder(store *state.Store, retention time.Duration) (*Recorder, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	r := &Recorder{
+		store:     store,
+		retention: retention,
+		open:      map[string]map[string]*Session{},
+	}
+
+	err := store.View(func(tx *state.Tx) error {
+		return tx.ForEach(sessionsBucket, func(key string, value []byte) error {
+			s := &Session{}
+			if err := json.Unmarshal(value, s); err != nil {
+				return fmt.Errorf("failed to parse session %q: %w", key, err)
+			}
+			if s.Online {
+				r.openSessions(s.Server)[s.SteamID] = s
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// openSessions returns the open sessions of the server, r.mu must be held
+func (r *Recorder) openSessions(server string) map[string]*Session {
+	open, ok := r.open[server]
+	if !ok {
+		open = map[string]*Session{}
+		r.open[server] = open
+	}
+	return open
+}
+
+// Observe records the players seen on the server at the given time. Sessions
+// of players no longer on the server end at their last observation. Players
+// without SteamID (e.g., bots) aren't recorded.
+func (r *Recorder) Observe(server string, players map[string]*models.Player, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	open := r.openSessions(server)
+	seen := map[string]struct{}{}
+	changed := []*Session{}
+
+	for _, p := range players {
+		if p.SteamID == "" || p.SteamID == "BOT" {
+			continue
+		}
+		seen[p.SteamID] = struct{}{}
+
+		s, ok := open[p.SteamID]
+		if ok && now.Sub(s.End) > SessionMaxGap {
+			s.Online = false
+			changed = append(changed, s)
+			ok = false
+		}
+		if !ok {
+			s = &Session{
+				Server:  server,
+				SteamID: p.SteamID,
+				Start:   now,
+				Online:  true,
+			}
+			open[p.SteamID] = s
+		}
+		s.Name = p.Username
+		s.End = now
+		changed = append(changed, s)
+	}
+	for steamID, s := range open {
+		if _, ok := seen[steamID]; !ok {
+			s.Online = false
+			changed = append(changed, s)
+			delete(open, steamID)
+		}
+	}
+
+	prune := now.Sub(r.lastPrune) >= pruneInterval
+	err := r.store.Update(func(tx *state.Tx) error {
+		for _, s := range changed {
+			if err := putSession(tx, s); err != nil {
+				return err
+			}
+		}
+		if prune {
+			return r.prune(tx, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if prune {
+		r.lastPrune = now
+	}
+	return nil
+}
+
+// putSession stores the session in both buckets
+func putSession(tx *state.Tx, s *Session) error {
+	if err := tx.Put(sessionsBucket, sessionKey(s.Server, s.Start, s.SteamID), s); err != nil {
+		return err
+	}
+	return tx.Put(playerSessionsBucket, sessionKey(s.SteamID, s.Start, s.Server), s)
+}
+
+// sessionKey returns the key of a session, keys of the same prefix are sorted
+// by start time
+func sessionKey(prefix string, start time.Time, suffix string) string {
+	return fmt.Sprintf("%s\x00%016x\x00%s", prefix, start.UnixNano(), suffix)
+}
+
+// prune deletes sessions which ended more than the retention before now
+func (r *Recorder) prune(tx *state.Tx, now time.Time) error {
+	cutoff := now.Add(-r.retention)
+	expired := []*Session{}
+	err := tx.ForEach(sessionsBucket, func(key string, value []byte) error {
+		s := &Session{}
+		if err := json.Unmarshal(value, s); err != nil {
+			return fmt.Errorf("failed to parse session %q: %w", key, err)
+		}
+		if !s.Online && s.End.Before(cutoff) {
+			expired = append(expired, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range expired {
+		if err := tx.Delete(sessionsBucket, sessionKey(s.Server, s.Start, s.SteamID)); err != nil {
+			return err
+		}
+		if err := tx.Delete(playerSessionsBucket, sessionKey(s.SteamID, s.Start, s.Server)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sessions returns the sessions matching the filter, the most recent first.
+// Sessions whose server wasn't seen for longer than SessionMaxGap aren't
+// reported as online.
+func (r *Recorder) Sessions(f Filter, now time.Time) ([]*Session, error) {
+	bucket, prefix := sessionsBucket, ""
+	switch {
+	case f.SteamID != "":
+		bucket, prefix = playerSessionsBucket, f.SteamID+"\x00"
+	case f.Server != "":
+		prefix = f.Server + "\x00"
+	}
+
+	sessions := []*Session{}
+	err := r.store.View(func(tx *state.Tx) error {
+		return tx.ForEachPrefix(bucket, prefix, func(key string, value []byte) error {
+			s := &Session{}
+			if err := json.Unmarshal(value, s); err != nil {
+				return fmt.Errorf("failed to parse session %q: %w", key, err)
+			}
+			if f.matches(s) {
+				s.Online = s.Online && now.Sub(s.End) <= SessionMaxGap
+				sessions = append(sessions, s)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(sessions, func(a, b *Session) int {
+		return b.Start.Compare(a.Start)
+	})
+	if f.Limit > 0 && len(sessions) > f.Limit {
+		sessions = sessions[:f.Limit]
+	}
+	return sessions, nil
+}
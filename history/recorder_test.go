@@ -0,0 +1,177 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/galexrt/srcds_exporter/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func openStore(t *testing.T, file string) *state.Store {
+	store, err := state.Open(file)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// players returns the players with the given SteamIDs, named after them
+func players(steamIDs ...string) map[string]*models.Player {
+	out := map[string]*models.Player{}
+	for _, id := range steamIDs {
+		out[id] = &models.Player{Username: "name of " + id, SteamID: id}
+	}
+	return out
+}
+
+func TestRecorderSessions(t *testing.T) {
+	r, err := NewRecorder(openStore(t, filepath.Join(t.TempDir(), "state.db")), 0)
+	require.NoError(t, err)
+
+	observations := []struct {
+		server  string
+		players map[string]*models.Player
+		after   time.Duration
+	}{
+		{"server1", players("[U:1:1]", "[U:1:2]", "BOT"), 0},
+		{"server1", players("[U:1:1]"), time.Minute},
+		{"server2", players("[U:1:2]"), 2 * time.Minute},
+		{"server1", players("[U:1:1]"), 3 * time.Minute},
+		// Exporter was down, the session of [U:1:2] ends at its last observation
+		{"server2", players("[U:1:2]"), time.Hour},
+		{"server1", players(), time.Hour},
+	}
+	for _, o := range observations {
+		require.NoError(t, r.Observe(o.server, o.players, start.Add(o.after)))
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []*Session
+	}{
+		{
+			name:   "player",
+			filter: Filter{SteamID: "[U:1:2]"},
+			expected: []*Session{
+				{Server: "server2", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start.Add(time.Hour), End: start.Add(time.Hour), Online: true},
+				{Server: "server2", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start.Add(2 * time.Minute), End: start.Add(2 * time.Minute)},
+				{Server: "server1", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start, End: start},
+			},
+		},
+		{
+			name:   "server",
+			filter: Filter{Server: "server1"},
+			expected: []*Session{
+				{Server: "server1", SteamID: "[U:1:1]", Name: "name of [U:1:1]", Start: start, End: start.Add(3 * time.Minute)},
+				{Server: "server1", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start, End: start},
+			},
+		},
+		{
+			name:   "player on server",
+			filter: Filter{SteamID: "[U:1:2]", Server: "server1"},
+			expected: []*Session{
+				{Server: "server1", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start, End: start},
+			},
+		},
+		{
+			name:   "time range",
+			filter: Filter{SteamID: "[U:1:2]", From: start.Add(time.Minute), To: start.Add(10 * time.Minute)},
+			expected: []*Session{
+				{Server: "server2", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start.Add(2 * time.Minute), End: start.Add(2 * time.Minute)},
+			},
+		},
+		{
+			name:   "limit",
+			filter: Filter{SteamID: "[U:1:2]", Limit: 1},
+			expected: []*Session{
+				{Server: "server2", SteamID: "[U:1:2]", Name: "name of [U:1:2]", Start: start.Add(time.Hour), End: start.Add(time.Hour), Online: true},
+			},
+		},
+		{
+			name:     "bots aren't recorded",
+			filter:   Filter{SteamID: "BOT"},
+			expected: []*Session{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sessions, err := r.Sessions(test.filter, start.Add(time.Hour))
+			require.NoError(t, err)
+			require.Len(t, sessions, len(test.expected))
+			for i, s := range sessions {
+				assert.True(t, test.expected[i].Start.Equal(s.Start))
+				assert.True(t, test.expected[i].End.Equal(s.End))
+				s.Start, s.End = test.expected[i].Start, test.expected[i].End
+				assert.Equal(t, test.expected[i], s)
+			}
+		})
+	}
+}
+
+func TestRecorderRestart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.db")
+
+	store, err := state.Open(file)
+	require.NoError(t, err)
+	r, err := NewRecorder(store, 0)
+	require.NoError(t, err)
+	require.NoError(t, r.Observe("server1", players("[U:1:1]"), start))
+	require.NoError(t, store.Close())
+
+	r, err = NewRecorder(openStore(t, file), 0)
+	require.NoError(t, err)
+	require.NoError(t, r.Observe("server1", players("[U:1:1]"), start.Add(time.Minute)))
+
+	sessions, err := r.Sessions(Filter{SteamID: "[U:1:1]"}, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, time.Minute, sessions[0].Duration())
+	assert.True(t, sessions[0].Online)
+
+	// Not seen for longer than the max gap
+	sessions, err = r.Sessions(Filter{SteamID: "[U:1:1]"}, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Online)
+}
+
+func TestRecorderRetention(t *testing.T) {
+	r, err := NewRecorder(openStore(t, filepath.Join(t.TempDir(), "state.db")), 24*time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Observe("server1", players("[U:1:1]"), start))
+	require.NoError(t, r.Observe("server1", players("[U:1:2]"), start.Add(2*time.Hour)))
+	require.NoError(t, r.Observe("server1", players(), start.Add(2*time.Hour+time.Minute)))
+	require.NoError(t, r.Observe("server1", players("[U:1:3]"), start.Add(25*time.Hour)))
+
+	sessions, err := r.Sessions(Filter{}, start.Add(25*time.Hour))
+	require.NoError(t, err)
+	steamIDs := []string{}
+	for _, s := range sessions {
+		steamIDs = append(steamIDs, s.SteamID)
+	}
+	// [U:1:2] was last seen less than the retention ago
+	assert.Equal(t, []string{"[U:1:3]", "[U:1:2]"}, steamIDs)
+}
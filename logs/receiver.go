@@ -0,0 +1,169 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs receives the logs game servers send via UDP to the addresses
+// added with the `logaddress_add` command.
+package logs
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"regexp"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var lineRegex = regexp.MustCompile(`^L \d{2}/\d{2}/\d{4} - \d{2}:\d{2}:\d{2}(?:\.\d+)?: (.*)$`)
+
+// Handler is called with the server label and the message of each log line
+type Handler func(server string, message string)
+
+// Receiver receives server logs on a UDP socket and attributes them to the
+// configured servers by the source address of the packets
+type Receiver struct {
+	log    *logrus.Entry
+	conn   net.PacketConn
+	secret string
+
+	mu sync.RWMutex
+	// servers resolved `ip:port` to server label
+	servers map[string]string
+	// hosts resolved ip to server labels, used when the port doesn't match
+	hosts map[string][]string
+}
+
+// NewReceiver creates a new Receiver reading from conn. When secret is set
+// only logs sent with that `sv_logsecret` are accepted, otherwise all logs.
+func NewReceiver(log *logrus.Logger, conn net.PacketConn, secret string) *Receiver {
+	return &Receiver{
+		log:     log.WithFields(logrus.Fields{"component": "logs"}),
+		conn:    conn,
+		secret:  secret,
+		servers: map[string]string{},
+		hosts:   map[string][]string{},
+	}
+}
+
+// SetServers sets the addresses of the servers logs are accepted from, the
+// address is used as the server label
+func (r *Receiver) SetServers(addrs []string) {
+	servers := make(map[string]string, len(addrs))
+	hosts := map[string][]string{}
+	for _, addr := range addrs {
+		resolved, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			r.log.Warnf("failed to resolve server address %s, ignoring its logs: %s", addr, err)
+			continue
+		}
+		servers[resolved.String()] = addr
+		ip := resolved.IP.String()
+		hosts[ip] = append(hosts[ip], addr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.servers = servers
+	r.hosts = hosts
+}
+
+// server returns the server label of the source address
+func (r *Receiver) server(addr net.Addr) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if server, ok := r.servers[addr.String()]; ok {
+		return server, true
+	}
+	// Logs may be sent from another port, e.g., behind NAT
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		if servers := r.hosts[udpAddr.IP.String()]; len(servers) == 1 {
+			return servers[0], true
+		}
+	}
+	return "", false
+}
+
+// Run reads log packets until ctx is done and calls handler for every line
+func (r *Receiver) Run(ctx context.Context, handler Handler) {
+	go func() {
+		<-ctx.Done()
+		r.conn.Close()
+	}()
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.log.Errorf("failed to read log packet: %s", err)
+			}
+			return
+		}
+
+		message, ok := ParsePacket(buf[:n], r.secret)
+		if !ok {
+			r.log.Debugf("ignoring invalid log packet from %s", addr)
+			continue
+		}
+		server, ok := r.server(addr)
+		if !ok {
+			r.log.Debugf("ignoring log packet from unknown server %s", addr)
+			continue
+		}
+		handler(server, message)
+	}
+}
+
+// ParsePacket returns the message of a log packet. The packet starts with
+// `\xFF\xFF\xFF\xFFR`, or `\xFF\xFF\xFF\xFFS<secret>` when `sv_logsecret` is
+// set, followed by the `L <date> - <time>: <message>` line.
+func ParsePacket(packet []byte, secret string) (string, bool) {
+	if !bytes.HasPrefix(packet, []byte{0xFF, 0xFF, 0xFF, 0xFF}) || len(packet) < 5 {
+		return "", false
+	}
+	line := packet[5:]
+	switch packet[4] {
+	case 'R':
+		if secret != "" {
+			return "", false
+		}
+	case 'S':
+		if secret == "" {
+			// Without a configured secret any secret is accepted
+			i := bytes.Index(line, []byte("L "))
+			if i < 0 {
+				return "", false
+			}
+			line = line[i:]
+			break
+		}
+		if !bytes.HasPrefix(line, []byte(secret)) {
+			return "", false
+		}
+		line = line[len(secret):]
+	default:
+		return "", false
+	}
+
+	line = bytes.TrimRight(line, "\x00\r\n")
+	match := lineRegex.FindSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	return string(match[1]), true
+}
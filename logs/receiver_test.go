@@ -0,0 +1,127 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var parsePacketTests = []struct {
+	name     string
+	packet   string
+	secret   string
+	expected string
+	ok       bool
+}{
+	{
+		name:     "plain",
+		packet:   "\xFF\xFF\xFF\xFFRL 10/19/2026 - 12:00:00: World triggered \"Round_Start\"\n\x00",
+		expected: `World triggered "Round_Start"`,
+		ok:       true,
+	},
+	{
+		name:     "milliseconds",
+		packet:   "\xFF\xFF\xFF\xFFRL 10/19/2026 - 12:00:00.123: Team \"CT\" scored \"1\" with \"5\" players\n\x00",
+		expected: `Team "CT" scored "1" with "5" players`,
+		ok:       true,
+	},
+	{
+		name:     "secret",
+		packet:   "\xFF\xFF\xFF\xFFS1234L 10/19/2026 - 12:00:00: Game Over: casual\n\x00",
+		secret:   "1234",
+		expected: "Game Over: casual",
+		ok:       true,
+	},
+	{
+		name:     "any secret accepted without configured secret",
+		packet:   "\xFF\xFF\xFF\xFFS1234L 10/19/2026 - 12:00:00: Game Over: casual\n\x00",
+		expected: "Game Over: casual",
+		ok:       true,
+	},
+	{
+		name:   "wrong secret",
+		packet: "\xFF\xFF\xFF\xFFS9999L 10/19/2026 - 12:00:00: Game Over: casual\n\x00",
+		secret: "1234",
+	},
+	{
+		name:   "missing secret",
+		packet: "\xFF\xFF\xFF\xFFRL 10/19/2026 - 12:00:00: Game Over: casual\n\x00",
+		secret: "1234",
+	},
+	{
+		name:   "no log line",
+		packet: "\xFF\xFF\xFF\xFFRhello\n\x00",
+	},
+	{
+		name:   "no header",
+		packet: "L 10/19/2026 - 12:00:00: Game Over: casual\n",
+	},
+}
+
+func TestParsePacket(t *testing.T) {
+	for _, tt := range parsePacketTests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, ok := ParsePacket([]byte(tt.packet), tt.secret)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := NewReceiver(logrus.New(), conn, "")
+
+	// Logs of unknown are ignored, as two servers share its IP the port
+	// has to match
+	known, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer known.Close()
+	unknown, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer unknown.Close()
+	r.SetServers([]string{known.LocalAddr().String(), "localhost:27015"})
+
+	type line struct{ server, message string }
+	lines := make(chan line, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx, func(server string, message string) {
+		lines <- line{server, message}
+	})
+
+	_, err = unknown.WriteTo([]byte("\xFF\xFF\xFF\xFFRL 10/19/2026 - 12:00:00: unknown\n\x00"), conn.LocalAddr())
+	require.NoError(t, err)
+	_, err = known.WriteTo([]byte("\xFF\xFF\xFF\xFFRL 10/19/2026 - 12:00:00: known\n\x00"), conn.LocalAddr())
+	require.NoError(t, err)
+
+	select {
+	case l := <-lines:
+		assert.Equal(t, line{known.LocalAddr().String(), "known"}, l)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no log line received")
+	}
+	assert.Empty(t, lines)
+}
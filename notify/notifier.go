@@ -0,0 +1,243 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify detects events of the servers (connection lost and restored,
+// map changes and player count thresholds) from the scraped data and sends
+// them to webhooks.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Event a server event sent to the webhooks
+type Event struct {
+	Type config.EventType `json:"type"`
+	// Server address of the server
+	Server string `json:"server"`
+	// Name of the server in the config, empty for unknown servers
+	Name    string    `json:"name,omitempty"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+
+	// Error why the connection was lost
+	Error string `json:"error,omitempty"`
+	// Map current map, PreviousMap the map before a map change
+	Map         string `json:"map,omitempty"`
+	PreviousMap string `json:"previousMap,omitempty"`
+	Players     int    `json:"players"`
+	MaxPlayers  int    `json:"maxPlayers"`
+	// Threshold crossed player threshold, Direction `above` or `below`
+	Threshold int    `json:"threshold,omitempty"`
+	Direction string `json:"direction,omitempty"`
+}
+
+// serverState state of a server as of the last observation
+type serverState struct {
+	up       bool
+	failures int
+	// lost set when a connection lost event was sent for the server
+	lost    bool
+	mapName string
+	players int
+}
+
+// Notifier detects events of the servers and sends them to the webhooks. It is
+// safe for concurrent use.
+type Notifier struct {
+	log *logrus.Entry
+
+	mu     sync.Mutex
+	cfg    config.Notifications
+	names  map[string]string
+	states map[string]*serverState
+
+	webhooks []*webhook
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewNotifier creates a notifier without webhooks, see Apply
+func NewNotifier(log *logrus.Logger) *Notifier {
+	return &Notifier{
+		log:    log.WithField("component", "notify"),
+		names:  map[string]string{},
+		states: map[string]*serverState{},
+	}
+}
+
+// Apply sets the notification settings and the names of the servers by
+// address. The webhooks are only restarted when their settings changed, the
+// state of servers no longer known is dropped.
+func (n *Notifier) Apply(cfg config.Notifications, names map[string]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.names = names
+	for server := range n.states {
+		if _, ok := names[server]; !ok {
+			delete(n.states, server)
+		}
+	}
+
+	if reflect.DeepEqual(cfg, n.cfg) {
+		return
+	}
+	n.cfg = cfg
+	n.stopWebhooks()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.webhooks = make([]*webhook, 0, len(cfg.Webhooks))
+	for _, w := range cfg.Webhooks {
+		hook := newWebhook(n.log.WithField("webhook", w.Name), w)
+		n.webhooks = append(n.webhooks, hook)
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			hook.run(ctx)
+		}()
+	}
+}
+
+// stopWebhooks stops the webhooks, queued events are dropped. n.mu must be held.
+func (n *Notifier) stopWebhooks() {
+	if n.cancel != nil {
+		n.cancel()
+		n.wg.Wait()
+		n.cancel = nil
+	}
+	n.webhooks = nil
+}
+
+// Close stops the webhooks
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopWebhooks()
+	n.cfg = config.Notifications{}
+}
+
+// Observe detects events from the result of scraping the server, either its
+// snapshot or the error. The first observation of a server only records its
+// state.
+func (n *Notifier) Observe(server string, snapshot *models.Snapshot, err error, now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.webhooks) == 0 {
+		return
+	}
+
+	event := Event{
+		Server: server,
+		Name:   n.names[server],
+		Time:   now,
+	}
+	state, known := n.states[server]
+	if !known {
+		state = &serverState{}
+		n.states[server] = state
+	}
+
+	if err != nil {
+		state.failures++
+		lostAfter := max(n.cfg.ConnectionLostAfter, 1)
+		if !known || (state.up && state.failures >= lostAfter) {
+			if known {
+				event.Type = config.ConnectionLostEvent
+				event.Error = err.Error()
+				event.Map = state.mapName
+				event.Message = fmt.Sprintf("Server %s is unreachable: %s", event.display(), err)
+				n.dispatch(event)
+				state.lost = true
+			}
+			state.up = false
+		}
+		return
+	}
+
+	status := snapshot.Status
+	event.Map = status.Map
+	event.Players = status.PlayerCount.Current
+	event.MaxPlayers = status.PlayerCount.Max
+	previous := *state
+	*state = serverState{
+		up:      true,
+		mapName: status.Map,
+		players: status.PlayerCount.Current,
+	}
+	if !known {
+		return
+	}
+	if !previous.up {
+		// Servers down since their first observation only record the state
+		if previous.lost {
+			event.Type = config.ConnectionRestoredEvent
+			event.Message = fmt.Sprintf("Server %s is reachable again, playing %s with %d/%d players", event.display(), event.Map, event.Players, event.MaxPlayers)
+			n.dispatch(event)
+		}
+		return
+	}
+
+	if previous.mapName != "" && status.Map != "" && previous.mapName != status.Map {
+		e := event
+		e.Type = config.MapChangedEvent
+		e.PreviousMap = previous.mapName
+		e.Message = fmt.Sprintf("Server %s changed the map from %s to %s", e.display(), previous.mapName, status.Map)
+		n.dispatch(e)
+	}
+	for _, threshold := range n.cfg.PlayerThresholds {
+		e := event
+		e.Type = config.PlayerThresholdEvent
+		e.Threshold = threshold
+		switch {
+		case previous.players < threshold && event.Players >= threshold:
+			e.Direction = "above"
+			e.Message = fmt.Sprintf("Server %s reached %d players (%d/%d)", e.display(), threshold, e.Players, e.MaxPlayers)
+		case previous.players >= threshold && event.Players < threshold:
+			e.Direction = "below"
+			e.Message = fmt.Sprintf("Server %s dropped below %d players (%d/%d)", e.display(), threshold, e.Players, e.MaxPlayers)
+		default:
+			continue
+		}
+		n.dispatch(e)
+	}
+}
+
+// dispatch queues the event on the webhooks subscribed to its type. n.mu must
+// be held.
+func (n *Notifier) dispatch(event Event) {
+	n.log.WithField("server", event.Server).Debugf("Event %s: %s", event.Type, event.Message)
+	for _, w := range n.webhooks {
+		w.enqueue(event)
+	}
+}
+
+// display returns the name and address of the server for messages
+func (e *Event) display() string {
+	if e.Name == "" {
+		return e.Server
+	}
+	return fmt.Sprintf("%s (%s)", e.Name, e.Server)
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// receiver starts a webhook receiver answering with the given status codes in
+// order, 204 once they are used up. The bodies of successful requests are
+// sent to the returned channel.
+func receiver(t *testing.T, statuses ...int) (string, <-chan []byte, *atomic.Int32) {
+	bodies := make(chan []byte, 100)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, bodies, &requests
+}
+
+// receive returns the next body sent to the receiver
+func receive(t *testing.T, bodies <-chan []byte) []byte {
+	select {
+	case body := <-bodies:
+		return body
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+		return nil
+	}
+}
+
+// assertNone asserts that no further notification is received
+func assertNone(t *testing.T, bodies <-chan []byte) {
+	select {
+	case body := <-bodies:
+		t.Errorf("unexpected notification %s", body)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func newTestNotifier(t *testing.T, cfg config.Notifications) *Notifier {
+	n := NewNotifier(logrus.New())
+	n.Apply(cfg, map[string]string{"127.0.0.1:27015": "server1"})
+	t.Cleanup(n.Close)
+	return n
+}
+
+func snapshot(mapName string, players int) *models.Snapshot {
+	return &models.Snapshot{
+		Status: models.Status{
+			Map:         mapName,
+			PlayerCount: models.PlayerCount{Current: players, Max: 24},
+		},
+	}
+}
+
+func TestNotifierEvents(t *testing.T) {
+	url, bodies, _ := receiver(t)
+	n := newTestNotifier(t, config.Notifications{
+		Webhooks: []config.Webhook{
+			{Name: "test", URL: config.Secret(url), MaxPerMinute: 600},
+		},
+		PlayerThresholds:    []int{10, 20},
+		ConnectionLostAfter: 2,
+	})
+
+	timeout := errors.New("i/o timeout")
+	observations := []struct {
+		snapshot *models.Snapshot
+		err      error
+		expected []Event
+	}{
+		// The first observation only records the state
+		{snapshot: snapshot("de_dust2", 5)},
+		{
+			snapshot: snapshot("de_dust2", 12),
+			expected: []Event{
+				{Type: config.PlayerThresholdEvent, Map: "de_dust2", Players: 12, MaxPlayers: 24, Threshold: 10, Direction: "above", Message: "Server server1 (127.0.0.1:27015) reached 10 players (12/24)"},
+			},
+		},
+		{
+			snapshot: snapshot("de_inferno", 25),
+			expected: []Event{
+				{Type: config.MapChangedEvent, Map: "de_inferno", PreviousMap: "de_dust2", Players: 25, MaxPlayers: 24, Message: "Server server1 (127.0.0.1:27015) changed the map from de_dust2 to de_inferno"},
+				{Type: config.PlayerThresholdEvent, Map: "de_inferno", Players: 25, MaxPlayers: 24, Threshold: 20, Direction: "above", Message: "Server server1 (127.0.0.1:27015) reached 20 players (25/24)"},
+			},
+		},
+		// A single failure isn't enough with connectionLostAfter 2
+		{err: timeout},
+		{snapshot: snapshot("de_inferno", 25)},
+		{err: timeout},
+		{
+			err: timeout,
+			expected: []Event{
+				{Type: config.ConnectionLostEvent, Map: "de_inferno", Error: "i/o timeout", Message: "Server server1 (127.0.0.1:27015) is unreachable: i/o timeout"},
+			},
+		},
+		{err: timeout},
+		{
+			snapshot: snapshot("de_nuke", 3),
+			expected: []Event{
+				{Type: config.ConnectionRestoredEvent, Map: "de_nuke", Players: 3, MaxPlayers: 24, Message: "Server server1 (127.0.0.1:27015) is reachable again, playing de_nuke with 3/24 players"},
+			},
+		},
+		{
+			snapshot: snapshot("de_nuke", 19),
+			expected: []Event{
+				{Type: config.PlayerThresholdEvent, Map: "de_nuke", Players: 19, MaxPlayers: 24, Threshold: 10, Direction: "above", Message: "Server server1 (127.0.0.1:27015) reached 10 players (19/24)"},
+			},
+		},
+		{
+			snapshot: snapshot("de_nuke", 9),
+			expected: []Event{
+				{Type: config.PlayerThresholdEvent, Map: "de_nuke", Players: 9, MaxPlayers: 24, Threshold: 10, Direction: "below", Message: "Server server1 (127.0.0.1:27015) dropped below 10 players (9/24)"},
+			},
+		},
+	}
+
+	for i, o := range observations {
+		now := start.Add(time.Duration(i) * time.Minute)
+		n.Observe("127.0.0.1:27015", o.snapshot, o.err, now)
+		for _, expected := range o.expected {
+			var event Event
+			require.NoError(t, json.Unmarshal(receive(t, bodies), &event))
+			expected.Server = "127.0.0.1:27015"
+			expected.Name = "server1"
+			assert.True(t, now.Equal(event.Time), "observation %d", i)
+			event.Time = time.Time{}
+			assert.Equal(t, expected, event, "observation %d", i)
+		}
+	}
+	assertNone(t, bodies)
+}
+
+func TestWebhookDiscord(t *testing.T) {
+	url, bodies, _ := receiver(t)
+	n := newTestNotifier(t, config.Notifications{
+		Webhooks: []config.Webhook{
+			{Name: "discord", URL: config.Secret(url), Format: config.DiscordWebhookFormat, Events: []config.EventType{config.MapChangedEvent}},
+		},
+		PlayerThresholds: []int{1},
+	})
+
+	n.Observe("127.0.0.1:27015", snapshot("de_dust2", 0), nil, start)
+	// The player threshold isn't subscribed
+	n.Observe("127.0.0.1:27015", snapshot("de_inferno", 1), nil, start.Add(time.Minute))
+
+	assert.JSONEq(t, `{"username":"srcds_exporter","content":"Server server1 (127.0.0.1:27015) changed the map from de_dust2 to de_inferno"}`, string(receive(t, bodies)))
+	assertNone(t, bodies)
+}
+
+func TestNotifierFirstObservationDown(t *testing.T) {
+	url, bodies, _ := receiver(t)
+	n := newTestNotifier(t, config.Notifications{
+		Webhooks: []config.Webhook{
+			{Name: "test", URL: config.Secret(url), MaxPerMinute: 600},
+		},
+		PlayerThresholds: []int{1},
+	})
+
+	timeout := errors.New("i/o timeout")
+	n.Observe("127.0.0.1:27015", nil, timeout, start)
+	n.Observe("127.0.0.1:27015", nil, timeout, start.Add(time.Minute))
+	// No lost event was sent, so the server coming up isn't a restore
+	n.Observe("127.0.0.1:27015", snapshot("de_dust2", 5), nil, start.Add(2*time.Minute))
+	assertNone(t, bodies)
+
+	n.Observe("127.0.0.1:27015", snapshot("de_inferno", 5), nil, start.Add(3*time.Minute))
+	var event Event
+	require.NoError(t, json.Unmarshal(receive(t, bodies), &event))
+	assert.Equal(t, config.MapChangedEvent, event.Type)
+	assertNone(t, bodies)
+}
+
+func TestWebhookDiscordTruncate(t *testing.T) {
+	w := newWebhook(logrus.NewEntry(logrus.New()), config.Webhook{Format: config.DiscordWebhookFormat})
+
+	body, err := w.payload(Event{Message: strings.Repeat("ö", discordMaxContent+10)})
+	require.NoError(t, err)
+	var msg discordMessage
+	require.NoError(t, json.Unmarshal(body, &msg))
+	assert.True(t, utf8.ValidString(msg.Content))
+	assert.Equal(t, discordMaxContent, utf8.RuneCountInString(msg.Content))
+	assert.Equal(t, strings.Repeat("ö", discordMaxContent-3)+"...", msg.Content)
+
+	body, err = w.payload(Event{Message: strings.Repeat("ö", discordMaxContent)})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(body, &msg))
+	assert.Equal(t, strings.Repeat("ö", discordMaxContent), msg.Content)
+}
+
+func TestWebhookRetry(t *testing.T) {
+	backoff := retryBackoff
+	retryBackoff = 10 * time.Millisecond
+	defer func() { retryBackoff = backoff }()
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		delivered bool
+		requests  int32
+	}{
+		{name: "server errors", statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}, delivered: true, requests: 3},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500}, requests: 4},
+		{name: "client error", statuses: []int{http.StatusNotFound}, requests: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			url, bodies, requests := receiver(t, test.statuses...)
+			n := newTestNotifier(t, config.Notifications{
+				Webhooks: []config.Webhook{
+					{Name: "test", URL: config.Secret(url)},
+				},
+			})
+
+			n.Observe("127.0.0.1:27015", snapshot("de_dust2", 0), nil, start)
+			n.Observe("127.0.0.1:27015", snapshot("de_inferno", 0), nil, start.Add(time.Minute))
+
+			if test.delivered {
+				receive(t, bodies)
+			} else {
+				assertNone(t, bodies)
+			}
+			assert.Eventually(t, func() bool { return requests.Load() == test.requests }, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestWebhookRateLimit(t *testing.T) {
+	url, bodies, _ := receiver(t)
+	n := newTestNotifier(t, config.Notifications{
+		Webhooks: []config.Webhook{
+			{Name: "test", URL: config.Secret(url), MaxPerMinute: 1},
+		},
+	})
+
+	n.Observe("127.0.0.1:27015", snapshot("de_dust2", 0), nil, start)
+	n.Observe("127.0.0.1:27015", snapshot("de_inferno", 0), nil, start.Add(time.Minute))
+	n.Observe("127.0.0.1:27015", snapshot("de_nuke", 0), nil, start.Add(2*time.Minute))
+
+	receive(t, bodies)
+	// The second notification is delayed by a minute
+	assertNone(t, bodies)
+}
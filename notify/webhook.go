@@ -0,0 +1,206 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/galexrt/srcds_exporter/config"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxPerMinute = 30
+	defaultRetries      = 3
+	defaultTimeout      = 10 * time.Second
+
+	// queueSize events queued per webhook, further events are dropped
+	queueSize = 100
+	// maxBurst notifications sent at once before the rate limit applies
+	maxBurst = 5
+	// maxRetryAfter upper limit of the delay requested by a webhook
+	maxRetryAfter = time.Minute
+	// discordMaxContent maximum length of a Discord message
+	discordMaxContent = 2000
+)
+
+// retryBackoff delay before the first retry, doubled for each further retry
+var retryBackoff = time.Second
+
+// webhook sends the events to a webhook target
+type webhook struct {
+	log     *logrus.Entry
+	cfg     config.Webhook
+	client  *http.Client
+	limiter *rate.Limiter
+	queue   chan Event
+}
+
+func newWebhook(log *logrus.Entry, cfg config.Webhook) *webhook {
+	if cfg.Format == "" {
+		cfg.Format = config.JSONWebhookFormat
+	}
+	if cfg.MaxPerMinute == 0 {
+		cfg.MaxPerMinute = defaultMaxPerMinute
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = defaultRetries
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	return &webhook{
+		log:     log,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.MaxPerMinute)), min(cfg.MaxPerMinute, maxBurst)),
+		queue:   make(chan Event, queueSize),
+	}
+}
+
+// enqueue queues the event if the webhook is subscribed to its type
+func (w *webhook) enqueue(event Event) {
+	if len(w.cfg.Events) > 0 && !slices.Contains(w.cfg.Events, event.Type) {
+		return
+	}
+	select {
+	case w.queue <- event:
+	default:
+		w.log.Warnf("Dropping %s event of server %s, too many queued events", event.Type, event.Server)
+	}
+}
+
+// run sends the queued events until ctx is done
+func (w *webhook) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.queue:
+			if err := w.limiter.Wait(ctx); err != nil {
+				return
+			}
+			if err := w.send(ctx, event); err != nil {
+				w.log.Errorf("Failed to send %s event of server %s: %s", event.Type, event.Server, err)
+			}
+		}
+	}
+}
+
+// send posts the event, failed requests (network errors, 429 and 5xx
+// responses) are retried with an exponential backoff
+func (w *webhook) send(ctx context.Context, event Event) error {
+	body, err := w.payload(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt >= w.cfg.Retries {
+			return err
+		}
+
+		delay := max(backoff, retryAfter)
+		w.log.Debugf("Retrying %s event of server %s in %s: %s", event.Type, event.Server, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the payload once. The returned duration is negative if the
+// request must not be retried, otherwise the delay requested by the webhook.
+func (w *webhook) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(w.cfg.URL), bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "srcds_exporter")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// Don't log the URL, it may contain a token
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := time.Duration(0)
+		if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil {
+			retryAfter = min(time.Duration(seconds*float64(time.Second)), maxRetryAfter)
+		}
+		return retryAfter, fmt.Errorf("rate limited by webhook (status %d)", resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return -1, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// discordMessage body of a Discord webhook request
+type discordMessage struct {
+	Username string `json:"username"`
+	Content  string `json:"content"`
+}
+
+// payload returns the request body of the event in the webhook's format
+func (w *webhook) payload(event Event) ([]byte, error) {
+	switch w.cfg.Format {
+	case config.DiscordWebhookFormat:
+		content := event.Message
+		// The limit is in characters, multi-byte characters mustn't be cut
+		if utf8.RuneCountInString(content) > discordMaxContent {
+			content = string([]rune(content)[:discordMaxContent-3]) + "..."
+		}
+		return json.Marshal(discordMessage{
+			Username: "srcds_exporter",
+			Content:  content,
+		})
+	default:
+		return json.Marshal(event)
+	}
+}
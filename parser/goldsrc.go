@@ -0,0 +1,111 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+)
+
+// GoldSrcBotID unique ID of bots in the GoldSrc `status` output
+const GoldSrcBotID = "BOT"
+
+var (
+	goldSrcHostnameRegex    = regexp.MustCompile(`(?m)^hostname\s*:\s*(.*?)\s*$`)
+	goldSrcVersionRegex     = regexp.MustCompile(`(?m)^version\s*:\s*(.*?)\s*$`)
+	goldSrcMapRegex         = regexp.MustCompile(`(?m)^map\s*:\s*(\S+)`)
+	goldSrcPlayerCountRegex = regexp.MustCompile(`(?m)^players\s*:\s*([0-9]+) active \(([0-9]+) max\)`)
+	goldSrcPlayerRegex      = regexp.MustCompile(`(?m)^#\s*(?P<slot>[0-9]+)\s+"(?P<username>.*)"\s+(?P<userid>[0-9]+)\s+(?P<uniqueid>\S+)\s+(?P<frag>-?[0-9]+)\s+(?P<time>[0-9:]+)\s+(?P<ping>[0-9]+)\s+(?P<loss>[0-9]+)(\s+(?P<ip>([0-9]{1,3}\.){3}[0-9]{1,3}):(?P<connport>[0-9]+)|\s+\S+)?\s*$`)
+)
+
+// ParseGoldSrcStatus parse GoldSrc (HLDS) `status` command to retrieve the
+// server status. Bots are counted from the player list.
+func ParseGoldSrcStatus(input string) (*models.Status, error) {
+	input = strings.Replace(input, "\000", "", -1)
+
+	count := goldSrcPlayerCountRegex.FindStringSubmatch(input)
+	if len(count) < 3 {
+		return nil, errors.New("no player count found in input")
+	}
+	current, _ := strconv.Atoi(count[1])
+	max, _ := strconv.Atoi(count[2])
+
+	bots := 0
+	for _, m := range goldSrcPlayerRegex.FindAllStringSubmatch(input, -1) {
+		if m[goldSrcPlayerRegex.SubexpIndex("uniqueid")] == GoldSrcBotID {
+			bots++
+		}
+	}
+
+	status := &models.Status{
+		PlayerCount: models.PlayerCount{
+			Current: current,
+			Max:     max,
+			Humans:  current - bots,
+			Bots:    bots,
+		},
+	}
+	if m := goldSrcHostnameRegex.FindStringSubmatch(input); len(m) > 1 {
+		status.Hostname = m[1]
+	}
+	if m := goldSrcVersionRegex.FindStringSubmatch(input); len(m) > 1 {
+		status.Version = m[1]
+	}
+	if m := goldSrcMapRegex.FindStringSubmatch(input); len(m) > 1 {
+		status.Map = m[1]
+	}
+
+	return status, nil
+}
+
+// ParseGoldSrcPlayers parse GoldSrc (HLDS) `status` command to retrieve the
+// players on the server keyed by their unique ID (SteamID)
+func ParseGoldSrcPlayers(input string) map[string]*models.Player {
+	input = strings.Replace(input, "\000", "", -1)
+
+	players := map[string]*models.Player{}
+	names := goldSrcPlayerRegex.SubexpNames()
+	for _, match := range goldSrcPlayerRegex.FindAllStringSubmatch(input, -1) {
+		m := map[string]string{}
+		for i, name := range names {
+			if name != "" {
+				m[name] = match[i]
+			}
+		}
+
+		userID, _ := strconv.Atoi(m["userid"])
+		ping, _ := strconv.Atoi(m["ping"])
+		loss, _ := strconv.Atoi(m["loss"])
+		connPort, _ := strconv.Atoi(m["connport"])
+		players[m["uniqueid"]] = &models.Player{
+			Username: m["username"],
+			UserID:   userID,
+			SteamID:  m["uniqueid"],
+			State:    "active",
+			Ping:     ping,
+			Loss:     loss,
+			IP:       m["ip"],
+			ConnPort: connPort,
+		}
+	}
+
+	return players
+}
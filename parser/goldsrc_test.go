@@ -0,0 +1,89 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goldSrcStatus = `hostname:  [EU] Retro Counter-Strike 1.6
+version :  48/1.1.2.7/Stdio 8684 secure  (10)
+tcp/ip  :  203.0.113.10:27015
+map     :  de_dust2 at: 0 x, 0 y, 0 z
+players :  3 active (32 max)
+
+#      name userid uniqueid frag time ping loss adr
+# 1 "Player "One"" 12 STEAM_0:0:12345  12  5:03   45    0 198.51.100.2:27005
+# 2 "Player Two" 13 STEAM_0:1:67890   -1 1:02:10   60    2 198.51.100.3:27005
+# 3 "[BOT] Bob" 14 BOT   0  5:04    0    0
+3 users
+`
+
+func TestParseGoldSrcStatus(t *testing.T) {
+	status, err := ParseGoldSrcStatus(goldSrcStatus)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Status{
+		Hostname: "[EU] Retro Counter-Strike 1.6",
+		Version:  "48/1.1.2.7/Stdio 8684 secure  (10)",
+		Map:      "de_dust2",
+		PlayerCount: models.PlayerCount{
+			Current: 3,
+			Max:     32,
+			Humans:  2,
+			Bots:    1,
+		},
+	}, status)
+
+	_, err = ParseGoldSrcStatus("Bad rcon_password.\n")
+	assert.Error(t, err)
+}
+
+func TestParseGoldSrcPlayers(t *testing.T) {
+	assert.Equal(t, map[string]*models.Player{
+		"STEAM_0:0:12345": {
+			Username: `Player "One"`,
+			UserID:   12,
+			SteamID:  "STEAM_0:0:12345",
+			State:    "active",
+			Ping:     45,
+			IP:       "198.51.100.2",
+			ConnPort: 27005,
+		},
+		"STEAM_0:1:67890": {
+			Username: "Player Two",
+			UserID:   13,
+			SteamID:  "STEAM_0:1:67890",
+			State:    "active",
+			Ping:     60,
+			Loss:     2,
+			IP:       "198.51.100.3",
+			ConnPort: 27005,
+		},
+		"BOT": {
+			Username: "[BOT] Bob",
+			UserID:   14,
+			SteamID:  "BOT",
+			State:    "active",
+		},
+	}, ParseGoldSrcPlayers(goldSrcStatus))
+
+	assert.Empty(t, ParseGoldSrcPlayers(`players :  0 active (32 max)`))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+)
+
+var (
+	teamScoreRegex     = regexp.MustCompile(`^Team "([^"]+)" (?:scored|current score|final score) "(-?\d+)" with "(\d+)" players`)
+	teamTriggeredRegex = regexp.MustCompile(`^Team "[^"]+" triggered "[^"]+" \(CT "(\d+)"\) \(T "(\d+)"\)`)
+	worldTriggerRegex  = regexp.MustCompile(`^World triggered "([^"]+)"`)
+	mapStartRegex      = regexp.MustCompile(`^(?:Started map|Loading map) "([^"]+)"`)
+)
+
+// ParseMatchEvent parse a server log message (without the `L <date>: `
+// prefix) for team scores and round, match and map changes of CS, CS:GO and
+// TF2 style logs
+func ParseMatchEvent(message string) (*models.MatchEvent, bool) {
+	message = strings.TrimSpace(message)
+
+	if match := teamScoreRegex.FindStringSubmatch(message); match != nil {
+		score, _ := strconv.Atoi(match[2])
+		players, _ := strconv.Atoi(match[3])
+		return &models.MatchEvent{
+			Type:    models.TeamScoreEvent,
+			Team:    NormalizeTeam(match[1]),
+			Score:   score,
+			Players: players,
+		}, true
+	}
+	if match := teamTriggeredRegex.FindStringSubmatch(message); match != nil {
+		ct, _ := strconv.Atoi(match[1])
+		t, _ := strconv.Atoi(match[2])
+		return &models.MatchEvent{
+			Type:   models.RoundEndEvent,
+			Scores: map[string]int{"CT": ct, "TERRORIST": t},
+		}, true
+	}
+	if match := mapStartRegex.FindStringSubmatch(message); match != nil {
+		return &models.MatchEvent{Type: models.MapStartEvent, Map: match[1]}, true
+	}
+	if strings.HasPrefix(message, "Game Over:") {
+		return &models.MatchEvent{Type: models.GameOverEvent}, true
+	}
+
+	match := worldTriggerRegex.FindStringSubmatch(message)
+	if match == nil {
+		return nil, false
+	}
+	trigger := match[1]
+	switch {
+	case trigger == "Round_Start":
+		return &models.MatchEvent{Type: models.RoundStartEvent}, true
+	case trigger == "Round_End", trigger == "Round_Win", trigger == "Round_Stalemate":
+		return &models.MatchEvent{Type: models.RoundEndEvent}, true
+	case trigger == "Match_Start":
+		return &models.MatchEvent{Type: models.MatchStartEvent}, true
+	case trigger == "Game_Commencing", strings.HasPrefix(trigger, "Restart_Round_"):
+		return &models.MatchEvent{Type: models.RoundRestartEvent}, true
+	case trigger == "Warmup_Start":
+		return &models.MatchEvent{Type: models.WarmupStartEvent}, true
+	case trigger == "Warmup_End":
+		return &models.MatchEvent{Type: models.WarmupEndEvent}, true
+	case trigger == "Game_Over":
+		return &models.MatchEvent{Type: models.GameOverEvent}, true
+	}
+	return nil, false
+}
+
+// NormalizeTeam returns the same name for teams which are logged with
+// different names, e.g., `T` and `TERRORIST`
+func NormalizeTeam(team string) string {
+	if team == "T" {
+		return "TERRORIST"
+	}
+	return team
+}
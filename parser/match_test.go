@@ -0,0 +1,82 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var parseMatchEventTests = []struct {
+	message  string
+	expected *models.MatchEvent
+}{
+	{
+		`Team "CT" scored "5" with "4" players`,
+		&models.MatchEvent{Type: models.TeamScoreEvent, Team: "CT", Score: 5, Players: 4},
+	},
+	{
+		`Team "Red" final score "2" with "12" players`,
+		&models.MatchEvent{Type: models.TeamScoreEvent, Team: "Red", Score: 2, Players: 12},
+	},
+	{
+		`Team "TERRORIST" triggered "SFUI_Notice_Terrorists_Win" (CT "7") (T "9")`,
+		&models.MatchEvent{Type: models.RoundEndEvent, Scores: map[string]int{"CT": 7, "TERRORIST": 9}},
+	},
+	{
+		`World triggered "Round_Start"`,
+		&models.MatchEvent{Type: models.RoundStartEvent},
+	},
+	{
+		`World triggered "Round_Win" (winner "Blue")`,
+		&models.MatchEvent{Type: models.RoundEndEvent},
+	},
+	{
+		`World triggered "Match_Start" on "de_inferno"`,
+		&models.MatchEvent{Type: models.MatchStartEvent},
+	},
+	{
+		`World triggered "Restart_Round_(1_second)"`,
+		&models.MatchEvent{Type: models.RoundRestartEvent},
+	},
+	{
+		`World triggered "Warmup_Start"`,
+		&models.MatchEvent{Type: models.WarmupStartEvent},
+	},
+	{
+		`Game Over: competitive mg_active de_inferno score 16:10 after 45 min`,
+		&models.MatchEvent{Type: models.GameOverEvent},
+	},
+	{
+		`Started map "de_inferno" (CRC "-123456")`,
+		&models.MatchEvent{Type: models.MapStartEvent, Map: "de_inferno"},
+	},
+	{
+		`"Player<2><STEAM_1:0:1><CT>" say "Team "CT" scored "5" with "4" players"`,
+		nil,
+	},
+}
+
+func TestParseMatchEvent(t *testing.T) {
+	for _, tt := range parseMatchEventTests {
+		actual, ok := ParseMatchEvent(tt.message)
+		assert.Equal(t, tt.expected != nil, ok, tt.message)
+		assert.Equal(t, tt.expected, actual, tt.message)
+	}
+}
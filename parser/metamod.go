@@ -0,0 +1,77 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+)
+
+var (
+	metamodVersionRegex = regexp.MustCompile(`(?m)^\s*Metamod:Source version\s+(\S+)`)
+	metamodPluginRegex  = regexp.MustCompile(`^\[(\d+)\]\s+(?:<([^>]+)>\s+)?(.*?)(?:\s+\((.*?)\))?(?:\s+by\s+(.*))?$`)
+
+	// metamodStatuses maps the abbreviated statuses of older Metamod:Source builds
+	metamodStatuses = map[string]string{
+		"paus":   "paused",
+		"err":    "error",
+		"fail":   "failed",
+		"stop":   "stopped",
+		"run":    "running",
+		"loaded": "running",
+	}
+)
+
+// ParseMetamodVersion parse the `meta version` command output to retrieve
+// the Metamod:Source version, empty when Metamod:Source isn't loaded
+func ParseMetamodVersion(input string) string {
+	result := metamodVersionRegex.FindStringSubmatch(input)
+	if len(result) > 1 {
+		return result[1]
+	}
+	return ""
+}
+
+// ParseMetamodPlugins parse the `meta list` command output
+func ParseMetamodPlugins(input string) []models.MetamodPlugin {
+	plugins := []models.MetamodPlugin{}
+	for _, line := range strings.Split(input, "\n") {
+		match := metamodPluginRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		status := "running"
+		if match[2] != "" {
+			status = strings.ToLower(match[2])
+			if s, ok := metamodStatuses[status]; ok {
+				status = s
+			}
+		}
+		plugins = append(plugins, models.MetamodPlugin{
+			Index:   index,
+			Name:    match[3],
+			Version: match[4],
+			Author:  strings.TrimSpace(match[5]),
+			Status:  status,
+		})
+	}
+	return plugins
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const metamodVersion = `Metamod:Source Version Information
+    Metamod:Source version 1.11.0-dev+1145
+    Plugin interface version: 16:14
+    SourceHook version: 5:5
+    Loaded As: Valve Server Plugin
+    Compiled on: Jul 11 2022 23:11:48
+    Built from: https://github.com/alliedmodders/metamod-source/commit/1a2b3c4
+    Build ID: 1145:1a2b3c4
+    http://www.metamodsource.net/
+`
+
+func TestParseMetamodVersion(t *testing.T) {
+	assert.Equal(t, "1.11.0-dev+1145", ParseMetamodVersion(metamodVersion))
+	assert.Equal(t, "", ParseMetamodVersion(`Unknown command "meta"`))
+}
+
+func TestParseMetamodPlugins(t *testing.T) {
+	assert.Equal(t, []models.MetamodPlugin{
+		{Index: 1, Name: "SourceMod", Version: "1.11.0.6911", Author: "AlliedModders LLC", Status: "running"},
+		{Index: 2, Name: "Stripper", Version: "1.2.2", Author: "BAILOPAN", Status: "paused"},
+		{Index: 3, Name: "addons/broken/broken_mm", Status: "error"},
+	}, ParseMetamodPlugins(`Listing 3 plugins:
+  [01] SourceMod (1.11.0.6911) by AlliedModders LLC
+  [02] <PAUS> Stripper (1.2.2) by BAILOPAN
+  [03] <Error> addons/broken/broken_mm
+`))
+
+	assert.Empty(t, ParseMetamodPlugins("No plugins loaded.\n"))
+}
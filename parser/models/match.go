@@ -0,0 +1,54 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+// MatchEventType type of a match related server log event
+type MatchEventType int
+
+const (
+	// TeamScoreEvent a team's score and player count, e.g., at round end
+	TeamScoreEvent MatchEventType = iota
+	// RoundStartEvent a round started
+	RoundStartEvent
+	// RoundEndEvent a round ended, Scores is set when the event has the team scores
+	RoundEndEvent
+	// RoundRestartEvent the round was restarted resetting the scores
+	RoundRestartEvent
+	// MatchStartEvent the match started (live)
+	MatchStartEvent
+	// WarmupStartEvent the warmup started
+	WarmupStartEvent
+	// WarmupEndEvent the warmup ended
+	WarmupEndEvent
+	// GameOverEvent the match ended
+	GameOverEvent
+	// MapStartEvent a map was loaded
+	MapStartEvent
+)
+
+// MatchEvent a match related event parsed from a server log line
+type MatchEvent struct {
+	Type MatchEventType
+	// Team, Score and Players of TeamScoreEvent
+	Team    string
+	Score   int
+	Players int
+	// Scores per team of a RoundEndEvent, nil when the event has none
+	Scores map[string]int
+	// Map of a MapStartEvent
+	Map string
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import "time"
+
+// Snapshot contains the server status, players and rules fetched together
+// from a server, so all collectors see the same state of the server.
+type Snapshot struct {
+	Time   time.Time
+	Status Status
+	// Players is nil when the connection mode doesn't expose players
+	Players map[string]*Player
+	// PlayersFromRCON is set when the players are from the RCON `status`
+	// output, only then they are keyed by their SteamID
+	PlayersFromRCON bool
+	// Rules is nil when the connection mode doesn't expose rules
+	Rules map[string]string
+}
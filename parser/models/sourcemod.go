@@ -0,0 +1,55 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+// SourceModPlugin a plugin from the `sm plugins list` output
+type SourceModPlugin struct {
+	Index   int
+	Name    string
+	Version string
+	Author  string
+	// Status is "running" or the lower cased status shown by SourceMod,
+	// e.g., "failed", "error" or "paused"
+	Status string
+}
+
+// Failed returns true when the plugin failed to load or errored
+func (p SourceModPlugin) Failed() bool {
+	switch p.Status {
+	case "failed", "error", "bad load":
+		return true
+	}
+	return false
+}
+
+// SourceModExtension an extension from the `sm exts list` output
+type SourceModExtension struct {
+	Index       int
+	Name        string
+	Version     string
+	Description string
+	// Status is "running", "failed" when the extension couldn't be loaded or
+	// "error" when it isn't running
+	Status string
+	// Error reason given for failed extensions
+	Error string
+}
+
+// Failed returns true when the extension failed to load or isn't running
+func (e SourceModExtension) Failed() bool {
+	return e.Status != "running"
+}
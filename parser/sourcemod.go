@@ -0,0 +1,104 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+)
+
+var (
+	sourceModVersionRegex   = regexp.MustCompile(`(?m)^\s*SourceMod Version:\s*(\S+)`)
+	sourceModPluginRegex    = regexp.MustCompile(`^(\d+)\s+(?:<([^>]+)>\s+)?"([^"]*)"(?:\s+\((.*?)\))?(?:\s+by\s+(.*))?$`)
+	sourceModExtensionRegex = regexp.MustCompile(`^\[(\d+)\]\s+(?:<([^>]+)>\s+)?(.*)$`)
+	sourceModExtInfoRegex   = regexp.MustCompile(`^(.*?)\s+\(([^)]*)\)(?::\s*(.*))?$`)
+	sourceModExtFailedRegex = regexp.MustCompile(`^file\s+"([^"]*)"(?::\s*|\s+reason:\s*)?(.*)$`)
+)
+
+// ParseSourceModVersion parse the `sm version` command output to retrieve
+// the SourceMod version, empty when SourceMod isn't loaded
+func ParseSourceModVersion(input string) string {
+	result := sourceModVersionRegex.FindStringSubmatch(input)
+	if len(result) > 1 {
+		return result[1]
+	}
+	return ""
+}
+
+// ParseSourceModPlugins parse the `sm plugins list` command output. The
+// errors section at the end of the output is ignored.
+func ParseSourceModPlugins(input string) []models.SourceModPlugin {
+	plugins := []models.SourceModPlugin{}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "Errors:" {
+			break
+		}
+		match := sourceModPluginRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		status := "running"
+		if match[2] != "" {
+			status = strings.ToLower(match[2])
+		}
+		plugins = append(plugins, models.SourceModPlugin{
+			Index:   index,
+			Name:    match[3],
+			Version: match[4],
+			Author:  strings.TrimSpace(match[5]),
+			Status:  status,
+		})
+	}
+	return plugins
+}
+
+// ParseSourceModExtensions parse the `sm exts list` command output
+func ParseSourceModExtensions(input string) []models.SourceModExtension {
+	exts := []models.SourceModExtension{}
+	for _, line := range strings.Split(input, "\n") {
+		match := sourceModExtensionRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		ext := models.SourceModExtension{
+			Index:  index,
+			Status: "running",
+		}
+		if match[2] != "" {
+			ext.Status = strings.ToLower(match[2])
+		}
+
+		if failed := sourceModExtFailedRegex.FindStringSubmatch(match[3]); failed != nil {
+			ext.Name = failed[1]
+			ext.Error = failed[2]
+		} else if info := sourceModExtInfoRegex.FindStringSubmatch(match[3]); info != nil {
+			ext.Name = info[1]
+			ext.Version = info[2]
+			ext.Description = info[3]
+		} else {
+			ext.Name = match[3]
+		}
+		exts = append(exts, ext)
+	}
+	return exts
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/galexrt/srcds_exporter/parser/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const sourceModVersion = ` SourceMod Version Information:
+    SourceMod Version: 1.11.0.6911
+    SourcePawn Engine: 1.11.0.6911, jit-x86 (build 1.11.0.6911)
+    SourcePawn API: v1 = 5, v2 = 16
+    Compiled on: Sep  6 2022 00:00:31
+    Built from: https://github.com/alliedmodders/sourcemod/commit/1a2b3c4
+    Build ID: 6911:1a2b3c4
+    http://www.sourcemod.net/
+`
+
+const sourceModPlugins = "[SM] Listing 5 plugins:\r\n" +
+	`  01 "Admin File Reader" (1.11.0.6911) by AlliedModders LLC
+  02 <Failed> "Fun Votes" (1.11.0.6911) by AlliedModders LLC
+  03 <Paused> "Nextmap" (1.11.0.6911) by AlliedModders LLC
+  04 "custom.smx"
+  05 <Error> "Zombie Reloaded" (3.1 (b2)) by Greyscale
+Errors:
+  funvotes.smx: Error detected in plugin startup (see error logs)
+`
+
+func TestParseSourceModVersion(t *testing.T) {
+	assert.Equal(t, "1.11.0.6911", ParseSourceModVersion(sourceModVersion))
+	assert.Equal(t, "", ParseSourceModVersion(`Unknown command "sm"`))
+}
+
+func TestParseSourceModPlugins(t *testing.T) {
+	plugins := ParseSourceModPlugins(sourceModPlugins)
+	assert.Equal(t, []models.SourceModPlugin{
+		{Index: 1, Name: "Admin File Reader", Version: "1.11.0.6911", Author: "AlliedModders LLC", Status: "running"},
+		{Index: 2, Name: "Fun Votes", Version: "1.11.0.6911", Author: "AlliedModders LLC", Status: "failed"},
+		{Index: 3, Name: "Nextmap", Version: "1.11.0.6911", Author: "AlliedModders LLC", Status: "paused"},
+		{Index: 4, Name: "custom.smx", Status: "running"},
+		{Index: 5, Name: "Zombie Reloaded", Version: "3.1 (b2)", Author: "Greyscale", Status: "error"},
+	}, plugins)
+
+	var failed int
+	for _, p := range plugins {
+		if p.Failed() {
+			failed++
+		}
+	}
+	assert.Equal(t, 2, failed)
+
+	assert.Empty(t, ParseSourceModPlugins(`Unknown command "sm"`))
+}
+
+func TestParseSourceModExtensions(t *testing.T) {
+	exts := ParseSourceModExtensions(`[SM] Displaying 3 extensions:
+[01] Automatic Updater (1.11.0.6911): Updates SourceMod gamedata files
+[02] <FAILED> file "dbi.mysql.ext.so": Could not find interface: IDBDriver
+[03] <FAILED> file "steamworks.ext.so" reason: Could not load library
+[04] SDK Tools (1.11.0.6911)
+`)
+	assert.Equal(t, []models.SourceModExtension{
+		{Index: 1, Name: "Automatic Updater", Version: "1.11.0.6911", Description: "Updates SourceMod gamedata files", Status: "running"},
+		{Index: 2, Name: "dbi.mysql.ext.so", Error: "Could not find interface: IDBDriver", Status: "failed"},
+		{Index: 3, Name: "steamworks.ext.so", Error: "Could not load library", Status: "failed"},
+		{Index: 4, Name: "SDK Tools", Version: "1.11.0.6911", Status: "running"},
+	}, exts)
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package state implements an embedded store persisting counters and history
+// of the exporter across restarts.
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// SchemaVersion version of the store layout written by this version
+	SchemaVersion = 1
+
+	// compactTxMaxSize size of the transactions used to copy the data when
+	// compacting
+	compactTxMaxSize = 64 << 20
+)
+
+var (
+	// ErrSchemaVersion the store was written by a newer version of the exporter
+	ErrSchemaVersion = errors.New("state: store schema version is newer than supported")
+
+	metaBucket       = []byte("meta")
+	schemaVersionKey = []byte("schemaVersion")
+
+	// migrations migrate the store from the schema version of the index to
+	// the next one
+	migrations = []func(tx *bolt.Tx) error{
+		// 0 -> 1: initial schema, buckets are created on first use
+		func(tx *bolt.Tx) error { return nil },
+	}
+)
+
+// Store embedded key value store, values are stored as JSON in buckets. It is
+// safe for concurrent use.
+type Store struct {
+	file string
+
+	mu sync.RWMutex
+	db *bolt.DB
+}
+
+// Open opens the store in file, creating it if it doesn't exist, and migrates
+// it to the current schema version
+func Open(file string) (*Store, error) {
+	s := &Store{file: file}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) open() error {
+	db, err := bolt.Open(s.file, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("state: failed to open %s: %w", s.file, err)
+	}
+	if err := db.Update(migrate); err != nil {
+		db.Close()
+		return err
+	}
+	s.db = db
+	return nil
+}
+
+// migrate runs the migrations from the schema version of the store to
+// SchemaVersion
+func migrate(tx *bolt.Tx) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+
+	version := uint64(0)
+	if v := meta.Get(schemaVersionKey); v != nil {
+		if len(v) != 8 {
+			return fmt.Errorf("state: invalid schema version")
+		}
+		version = binary.BigEndian.Uint64(v)
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("%w (%d > %d)", ErrSchemaVersion, version, SchemaVersion)
+	}
+
+	for ; version < SchemaVersion; version++ {
+		if err := migrations[version](tx); err != nil {
+			return fmt.Errorf("state: failed to migrate schema version %d: %w", version, err)
+		}
+	}
+	return meta.Put(schemaVersionKey, binary.BigEndian.AppendUint64(nil, version))
+}
+
+// File returns the file of the store
+func (s *Store) File() string {
+	return s.file
+}
+
+// Close closes the store
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db.Close()
+}
+
+// View runs fn in a read-only transaction
+func (s *Store) View(fn func(tx *Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// Update runs fn in a read-write transaction, which is rolled back when fn
+// returns an error
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// Compact rewrites the store to release the space of deleted and overwritten
+// values, and returns the size of the file before and after
+func (s *Store) Compact() (before int64, after int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info, err := os.Stat(s.file); err == nil {
+		before = info.Size()
+	}
+
+	tmpFile := s.file + ".compact"
+	dst, err := bolt.Open(tmpFile, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return before, before, fmt.Errorf("state: failed to compact: %w", err)
+	}
+	defer os.Remove(tmpFile)
+	if err := bolt.Compact(dst, s.db, compactTxMaxSize); err != nil {
+		dst.Close()
+		return before, before, fmt.Errorf("state: failed to compact: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return before, before, fmt.Errorf("state: failed to compact: %w", err)
+	}
+
+	if err := s.db.Close(); err != nil {
+		return before, before, err
+	}
+	if err := os.Rename(tmpFile, s.file); err != nil {
+		// Keep using the uncompacted store
+		return before, before, errors.Join(fmt.Errorf("state: failed to compact: %w", err), s.open())
+	}
+	if err := s.open(); err != nil {
+		return before, 0, err
+	}
+
+	if info, err := os.Stat(s.file); err == nil {
+		after = info.Size()
+	}
+	return before, after, nil
+}
+
+// Tx transaction of the store
+type Tx struct {
+	tx *bolt.Tx
+}
+
+// Get decodes the value of key in bucket into out, false is returned when the
+// key doesn't exist
+func (t *Tx) Get(bucket string, key string, out any) (bool, error) {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return false, nil
+	}
+	v := b.Get([]byte(key))
+	if v == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(v, out); err != nil {
+		return true, fmt.Errorf("state: failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Put stores value as key in bucket, the bucket is created if it doesn't exist
+func (t *Tx) Put(bucket string, key string, value any) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("state: failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return b.Put([]byte(key), v)
+}
+
+// Delete deletes key from bucket
+func (t *Tx) Delete(bucket string, key string) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
+
+// ForEach calls fn for each key in bucket in byte order, decode the value with
+// json.Unmarshal. fn must not modify the bucket.
+func (t *Tx) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		return fn(string(k), v)
+	})
+}
+
+// ForEachPrefix calls fn for each key in bucket starting with prefix in byte
+// order. fn must not modify the bucket.
+func (t *Tx) ForEachPrefix(bucket string, prefix string, fn func(key string, value []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+		if err := fn(string(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
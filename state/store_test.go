@@ -0,0 +1,157 @@
+/*
+Copyright 2026 Alexander Trost <galexrt@googlemail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type counter struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+func TestStorePersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.db")
+
+	s, err := Open(file)
+	require.NoError(t, err)
+	require.NoError(t, s.Update(func(tx *Tx) error {
+		if err := tx.Put("counters", "a", counter{Name: "a", Value: 1}); err != nil {
+			return err
+		}
+		return tx.Put("counters", "b", counter{Name: "b", Value: 2})
+	}))
+	require.NoError(t, s.Close())
+
+	s, err = Open(file)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.View(func(tx *Tx) error {
+		var c counter
+		found, err := tx.Get("counters", "b", &c)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, counter{Name: "b", Value: 2}, c)
+
+		found, err = tx.Get("counters", "missing", &c)
+		require.NoError(t, err)
+		assert.False(t, found)
+		found, err = tx.Get("missing", "a", &c)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		keys := []string{}
+		require.NoError(t, tx.ForEach("counters", func(key string, value []byte) error {
+			keys = append(keys, key)
+			return nil
+		}))
+		assert.Equal(t, []string{"a", "b"}, keys)
+
+		keys = keys[:0]
+		require.NoError(t, tx.ForEachPrefix("counters", "b", func(key string, value []byte) error {
+			keys = append(keys, key)
+			return nil
+		}))
+		assert.Equal(t, []string{"b"}, keys)
+		return nil
+	}))
+}
+
+func TestStoreUpdateRollback(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	err = s.Update(func(tx *Tx) error {
+		if err := tx.Put("counters", "a", counter{Value: 1}); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed")
+	})
+	assert.Error(t, err)
+
+	require.NoError(t, s.View(func(tx *Tx) error {
+		found, err := tx.Get("counters", "a", &counter{})
+		assert.False(t, found)
+		return err
+	}))
+}
+
+func TestStoreCompact(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	value := strings.Repeat("x", 4096)
+	require.NoError(t, s.Update(func(tx *Tx) error {
+		for i := range 1000 {
+			if err := tx.Put("history", fmt.Sprintf("%04d", i), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	require.NoError(t, s.Update(func(tx *Tx) error {
+		for i := range 999 {
+			if err := tx.Delete("history", fmt.Sprintf("%04d", i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	before, after, err := s.Compact()
+	require.NoError(t, err)
+	assert.Less(t, after, before)
+
+	require.NoError(t, s.View(func(tx *Tx) error {
+		var out string
+		found, err := tx.Get("history", "0999", &out)
+		assert.True(t, found)
+		assert.Equal(t, value, out)
+		return err
+	}))
+}
+
+func TestStoreSchemaVersion(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.db")
+
+	s, err := Open(file)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	// Written by a newer version of the exporter
+	db, err := bolt.Open(file, 0o600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(schemaVersionKey, binary.BigEndian.AppendUint64(nil, SchemaVersion+1))
+	}))
+	require.NoError(t, db.Close())
+
+	_, err = Open(file)
+	assert.ErrorIs(t, err, ErrSchemaVersion)
+}